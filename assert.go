@@ -0,0 +1,118 @@
+package diff
+
+import "testing"
+
+// AssertText compares expected and actual as Text does, and reports any
+// difference with t.Error. It returns true if the inputs are identical.
+//
+// All of the Assert and Require functions report errors which prevent the
+// comparison, such as an input of an unsupported type, or an unreadable golden
+// file, with t.Fatal, as they indicate a problem with the test itself. When
+// the expected value is a *File, its path is used as the expected label of
+// the diff, unless overridden with Labels.
+func AssertText(t testing.TB, expected, actual interface{}, opts ...Option) bool {
+	t.Helper()
+	return report(t, false, TextWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// RequireText is like AssertText, but reports any difference with t.Fatal.
+func RequireText(t testing.TB, expected, actual interface{}, opts ...Option) {
+	t.Helper()
+	report(t, true, TextWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// AssertJSON compares expected and actual as AsJSON does, and reports any
+// difference with t.Error. It returns true if the inputs are equivalent.
+func AssertJSON(t testing.TB, expected, actual interface{}, opts ...Option) bool {
+	t.Helper()
+	return report(t, false, AsJSONWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// RequireJSON is like AssertJSON, but reports any difference with t.Fatal.
+func RequireJSON(t testing.TB, expected, actual interface{}, opts ...Option) {
+	t.Helper()
+	report(t, true, AsJSONWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// AssertInterface compares expected and actual as Interface does, and reports
+// any difference with t.Error. It returns true if the inputs are equal.
+func AssertInterface(t testing.TB, expected, actual interface{}, opts ...Option) bool {
+	t.Helper()
+	return report(t, false, InterfaceWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// RequireInterface is like AssertInterface, but reports any difference with
+// t.Fatal.
+func RequireInterface(t testing.TB, expected, actual interface{}, opts ...Option) {
+	t.Helper()
+	report(t, true, InterfaceWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// AssertHTTPRequest compares expected and actual as HTTPRequest does, and
+// reports any difference with t.Error. It returns true if the inputs are
+// identical.
+func AssertHTTPRequest(t testing.TB, expected, actual interface{}, opts ...Option) bool {
+	t.Helper()
+	return report(t, false, HTTPRequestWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// RequireHTTPRequest is like AssertHTTPRequest, but reports any difference
+// with t.Fatal.
+func RequireHTTPRequest(t testing.TB, expected, actual interface{}, opts ...Option) {
+	t.Helper()
+	report(t, true, HTTPRequestWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// AssertHTTPResponse compares expected and actual as HTTPResponse does, and
+// reports any difference with t.Error. It returns true if the inputs are
+// identical.
+func AssertHTTPResponse(t testing.TB, expected, actual interface{}, opts ...Option) bool {
+	t.Helper()
+	return report(t, false, HTTPResponseWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// RequireHTTPResponse is like AssertHTTPResponse, but reports any difference
+// with t.Fatal.
+func RequireHTTPResponse(t testing.TB, expected, actual interface{}, opts ...Option) {
+	t.Helper()
+	report(t, true, HTTPResponseWith(expected, actual, assertOptions(t, expected, opts)...))
+}
+
+// AssertDir compares the contents of dir against expected, which may be a
+// map or a manifest *File, as DirChecksum does, and reports any difference with
+// t.Error. It returns true if the contents match.
+func AssertDir(t testing.TB, expected interface{}, dir string) bool {
+	t.Helper()
+	return report(t, false, DirChecksum(expected, dir))
+}
+
+// RequireDir is like AssertDir, but reports any difference with t.Fatal.
+func RequireDir(t testing.TB, expected interface{}, dir string) {
+	t.Helper()
+	report(t, true, DirChecksum(expected, dir))
+}
+
+// assertOptions prepends options to record the name of the test, and to
+// label the diff with the golden file's path, if expected is a *File, to opts.
+func assertOptions(t testing.TB, expected interface{}, opts []Option) []Option {
+	prefix := []Option{withTest(t.Name())}
+	if f, ok := expected.(*File); ok {
+		prefix = append(prefix, Labels(f.name(), "actual"))
+	}
+	return append(prefix, opts...)
+}
+
+// report reports d to t, rendered with Render, if it is non-nil, and returns
+// true if d is nil.
+func report(t testing.TB, fatal bool, d *Result) bool {
+	t.Helper()
+	if d == nil {
+		return true
+	}
+	if fatal || d.Err() != nil {
+		t.Fatal(d.Render())
+	} else {
+		t.Error(d.Render())
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package diff
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeTB records the failures reported to it.
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Error(args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *fakeTB) Fatal(args ...interface{}) {
+	t.Error(args...)
+	t.fatal = true
+}
+
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert func(testing.TB) bool
+		errors []string
+		fatal  bool
+		golden string
+	}{
+		{
+			name: "text equal",
+			assert: func(t testing.TB) bool {
+				return AssertText(t, "foo", "foo")
+			},
+		},
+		{
+			name: "text different",
+			assert: func(t testing.TB) bool {
+				return AssertText(t, "foo", "bar")
+			},
+			errors: []string{"--- expected\n+++ actual\n@@ -1 +1 @@\n-foo\n+bar\n"},
+		},
+		{
+			name: "require text",
+			assert: func(t testing.TB) bool {
+				RequireText(t, "foo", "bar")
+				return false
+			},
+			errors: []string{"--- expected\n+++ actual\n@@ -1 +1 @@\n-foo\n+bar\n"},
+			fatal:  true,
+		},
+		{
+			name: "text with options",
+			assert: func(t testing.TB) bool {
+				return AssertText(t, "foo\nbar\n", "foo\nbaz\n", ContextLines(0))
+			},
+			errors: []string{"--- expected\n+++ actual\n@@ -2 +2 @@\n-bar\n+baz\n"},
+		},
+		{
+			name: "golden file",
+			assert: func(t testing.TB) bool {
+				return AssertText(t, &File{Path: "testdata/test.txt"}, "Other Content\n")
+			},
+			errors: []string{"--- testdata/test.txt\n+++ actual\n@@ -1 +1 @@\n-Test Content\n+Other Content\n"},
+			golden: "testdata/test.txt",
+		},
+		{
+			name: "golden file, overridden labels",
+			assert: func(t testing.TB) bool {
+				return AssertText(t, &File{Path: "testdata/test.txt"}, "Other Content\n", Labels("a", "b"))
+			},
+			errors: []string{"--- a\n+++ b\n@@ -1 +1 @@\n-Test Content\n+Other Content\n"},
+			golden: "testdata/test.txt",
+		},
+		{
+			name: "comparison error",
+			assert: func(t testing.TB) bool {
+				return AssertText(t, 123, "foo")
+			},
+			errors: []string{"[diff] expected: input must be of type string, []byte, or io.Reader, not int"},
+			fatal:  true,
+		},
+		{
+			name: "json",
+			assert: func(t testing.TB) bool {
+				return AssertJSON(t, []byte(`{"a":1}`), map[string]int{"a": 2})
+			},
+			errors: []string{"--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n {\n-    \"a\": 1\n+    \"a\": 2\n }\n"},
+		},
+		{
+			name: "require json",
+			assert: func(t testing.TB) bool {
+				RequireJSON(t, []byte(`{"a":1}`), map[string]int{"a": 1})
+				return true
+			},
+		},
+		{
+			name: "interface",
+			assert: func(t testing.TB) bool {
+				return AssertInterface(t, 1, 2)
+			},
+			errors: []string{"--- expected\n+++ actual\n@@ -1 +1 @@\n-(int) 1\n+(int) 2\n"},
+		},
+		{
+			name: "require interface",
+			assert: func(t testing.TB) bool {
+				RequireInterface(t, 1, 2)
+				return false
+			},
+			errors: []string{"--- expected\n+++ actual\n@@ -1 +1 @@\n-(int) 1\n+(int) 2\n"},
+			fatal:  true,
+		},
+		{
+			name: "http request",
+			assert: func(t testing.TB) bool {
+				return AssertHTTPRequest(t, &File{Path: "testdata/request.raw"}, httptest.NewRequest("GET", "/", nil), ContextLines(0))
+			},
+			errors: []string{"--- testdata/request.raw\n+++ actual\n@@ -2,3 +2 @@\n-Host: localhost:6005\r\n-Accept: */*\r\n-User-Agent: curl/7.52.1\r\n+Host: example.com\r\n"},
+			golden: "testdata/request.raw",
+		},
+		{
+			name: "require http request",
+			assert: func(t testing.TB) bool {
+				RequireHTTPRequest(t, nil, nil)
+				return true
+			},
+		},
+		{
+			name: "http response",
+			assert: func(t testing.TB) bool {
+				return AssertHTTPResponse(t, nil, nil)
+			},
+		},
+		{
+			name: "require http response",
+			assert: func(t testing.TB) bool {
+				RequireHTTPResponse(t, 123, nil)
+				return false
+			},
+			errors: []string{"Failed to dump expected response: input must be of type *http.Response, io.Reader, string, or []byte, not int"},
+			fatal:  true,
+		},
+		{
+			name: "dir",
+			assert: func(t testing.TB) bool {
+				return AssertDir(t, map[string]string{}, "testdata/not_found")
+			},
+			errors: []string{"open testdata/not_found: no such file or directory"},
+			fatal:  true,
+		},
+		{
+			name: "require dir",
+			assert: func(t testing.TB) bool {
+				RequireDir(t, map[string]string{}, "testdata/not_found")
+				return false
+			},
+			errors: []string{"open testdata/not_found: no such file or directory"},
+			fatal:  true,
+		},
+	}
+	defer setenv(t, "NO_COLOR", "1")()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.golden != "" && Updating(test.golden) {
+				t.Skip("golden files would be overwritten in update mode")
+			}
+			tb := &fakeTB{TB: t}
+			ok := test.assert(tb)
+			if ok != (len(test.errors) == 0) {
+				t.Errorf("Unexpected return value: %t", ok)
+			}
+			if d := Interface(test.errors, tb.errors); d != nil {
+				t.Error(d)
+			}
+			if tb.fatal != test.fatal {
+				t.Errorf("Unexpected fatal: %t", tb.fatal)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"os"
+	"strings"
+)
+
+// ColorMode controls whether rendered diffs are colorized with ANSI escape
+// sequences.
+type ColorMode int
+
+const (
+	// ColorAuto colorizes output only when standard output is a terminal.
+	// Setting the NO_COLOR environment variable to any non-empty value
+	// disables color, and setting FORCE_COLOR to any value other than "",
+	// "0" or "false" enables it, regardless of the terminal. This is the
+	// default for Render, and for the Assert and Require functions. String
+	// and Error never colorize output, unless Color is passed explicitly.
+	ColorAuto ColorMode = iota
+	// ColorAlways always colorizes output.
+	ColorAlways
+	// ColorNever never colorizes output.
+	ColorNever
+
+	// colorUnset indicates that no color mode was selected. It renders as
+	// ColorNever.
+	colorUnset ColorMode = -1
+)
+
+// Color sets the color mode used to render the diff. When color is enabled,
+// deleted lines are shown in red, inserted lines in green, and hunk headers in
+// cyan. Intra-line highlights, if enabled with IntraLine, are shown in reverse
+// video rather than with markers.
+func Color(mode ColorMode) Option {
+	return func(o *options) {
+		o.color = mode
+	}
+}
+
+// stdoutIsTerminal reports whether standard output is a terminal. It is a
+// variable so it can be replaced in tests.
+var stdoutIsTerminal = func() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func (m ColorMode) enabled() bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever, colorUnset:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("FORCE_COLOR")) {
+	case "", "0", "false":
+	default:
+		return true
+	}
+	return stdoutIsTerminal()
+}
+
+// ANSI escape sequences.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiCyan    = "\x1b[36m"
+	ansiReverse = "\x1b[7m"
+	ansiNoRev   = "\x1b[27m"
+)
+
+// painter applies ANSI styles to text, if enabled.
+type painter bool
+
+func (p painter) paint(style, text string) string {
+	if !p || text == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// lineStyle returns the style of a line of the given kind.
+func lineStyle(kind LineKind) string {
+	switch kind {
+	case Delete:
+		return ansiRed
+	case Insert:
+		return ansiGreen
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"os"
+	"testing"
+)
+
+// setenv sets an environment variable, and returns a function to restore its
+// previous state.
+func setenv(t *testing.T, key, value string) func() {
+	t.Helper()
+	old, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+func TestColorModeEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ColorMode
+		terminal bool
+		noColor  string
+		force    string
+		expected bool
+	}{
+		{
+			name:     "always",
+			mode:     ColorAlways,
+			noColor:  "1",
+			expected: true,
+		},
+		{
+			name:     "never",
+			mode:     ColorNever,
+			terminal: true,
+			force:    "1",
+			expected: false,
+		},
+		{
+			name:     "auto, terminal",
+			terminal: true,
+			expected: true,
+		},
+		{
+			name:     "auto, not a terminal",
+			expected: false,
+		},
+		{
+			name:     "auto, NO_COLOR",
+			terminal: true,
+			noColor:  "1",
+			expected: false,
+		},
+		{
+			name:     "auto, FORCE_COLOR",
+			force:    "1",
+			expected: true,
+		},
+		{
+			name:     "auto, FORCE_COLOR=false",
+			terminal: true,
+			force:    "false",
+			expected: true,
+		},
+		{
+			name:     "auto, FORCE_COLOR=0",
+			force:    "0",
+			expected: false,
+		},
+	}
+	defer func(f func() bool) { stdoutIsTerminal = f }(stdoutIsTerminal)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdoutIsTerminal = func() bool { return test.terminal }
+			defer setenv(t, "NO_COLOR", test.noColor)()
+			defer setenv(t, "FORCE_COLOR", test.force)()
+			if result := test.mode.enabled(); result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
+
+func TestColorRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		result string
+	}{
+		{
+			name:   "never",
+			opts:   []Option{Color(ColorNever)},
+			result: "--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n foo\n-bar 1\n+bar 2\n",
+		},
+		{
+			name: "always",
+			opts: []Option{Color(ColorAlways)},
+			result: "\x1b[1m--- expected\x1b[0m\n\x1b[1m+++ actual\x1b[0m\n\x1b[36m@@ -1,2 +1,2 @@\x1b[0m\n" +
+				" foo\n\x1b[31m-bar 1\x1b[0m\n\x1b[32m+bar 2\x1b[0m\n",
+		},
+		{
+			name: "intra-line",
+			opts: []Option{Color(ColorAlways), IntraLine(Words)},
+			result: "\x1b[1m--- expected\x1b[0m\n\x1b[1m+++ actual\x1b[0m\n\x1b[36m@@ -1,2 +1,2 @@\x1b[0m\n" +
+				" foo\n\x1b[31m-bar \x1b[7m1\x1b[27m\x1b[0m\n\x1b[32m+bar \x1b[7m2\x1b[27m\x1b[0m\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := TextWith("foo\nbar 1\n", "foo\nbar 2\n", test.opts...)
+			if d := Interface(test.result, result.String()); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestColorDefault(t *testing.T) {
+	defer setenv(t, "NO_COLOR", "")()
+	defer setenv(t, "FORCE_COLOR", "1")()
+	result := Text("foo\nbar 1\n", "foo\nbar 2\n")
+	plain := "--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n foo\n-bar 1\n+bar 2\n"
+	t.Run("String", func(t *testing.T) {
+		if d := Interface(plain, result.String()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		if d := Interface(plain, result.Error()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("Render", func(t *testing.T) {
+		colored := "\x1b[1m--- expected\x1b[0m\n\x1b[1m+++ actual\x1b[0m\n\x1b[36m@@ -1,2 +1,2 @@\x1b[0m\n" +
+			" foo\n\x1b[31m-bar 1\x1b[0m\n\x1b[32m+bar 2\x1b[0m\n"
+		if d := Interface(colored, result.Render()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("Render, explicit", func(t *testing.T) {
+		if d := Interface(plain, result.Render(Color(ColorNever))); d != nil {
+			t.Error(d)
+		}
+	})
+}
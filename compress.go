@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how a golden File is compressed.
+type Compression int
+
+const (
+	// DetectCompression selects the compression by the file's extension:
+	// ".gz" for Gzip, or ".zst" for Zstd. Other files are uncompressed. This
+	// is the default.
+	DetectCompression Compression = iota
+	// Uncompressed disables compression, regardless of the extension.
+	Uncompressed
+	// Gzip selects gzip compression.
+	Gzip
+	// Zstd selects Zstandard compression.
+	Zstd
+)
+
+// resolve returns the compression to use for the file at path.
+func (c Compression) resolve(path string) Compression {
+	if c != DetectCompression {
+		return c
+	}
+	switch filepath.Ext(path) {
+	case ".gz":
+		return Gzip
+	case ".zst":
+		return Zstd
+	}
+	return Uncompressed
+}
+
+// decompress returns the decompressed data. Empty data, as for a missing
+// file, decompresses to nothing.
+func (c Compression) decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	switch c {
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case Zstd:
+		d, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer d.Close()
+		return d.DecodeAll(data, nil)
+	}
+	return data, nil
+}
+
+// compress returns the compressed data. The output depends only on the
+// input, so that unchanged content produces an unchanged file.
+func (c Compression) compress(data []byte) ([]byte, error) {
+	switch c {
+	case Gzip:
+		buf := &bytes.Buffer{}
+		// The zero header omits the modification time and file name.
+		w, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, e := w.Write(data); e != nil {
+			return nil, e
+		}
+		if e := w.Close(); e != nil {
+			return nil, e
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		e, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer e.Close()
+		return e.EncodeAll(data, nil), nil
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressionResolve(t *testing.T) {
+	tests := []struct {
+		compression Compression
+		path        string
+		expected    Compression
+	}{
+		{DetectCompression, "foo.json.gz", Gzip},
+		{DetectCompression, "foo.json.zst", Zstd},
+		{DetectCompression, "foo.json", Uncompressed},
+		{Uncompressed, "foo.json.gz", Uncompressed},
+		{Zstd, "foo.json", Zstd},
+	}
+	for _, test := range tests {
+		if result := test.compression.resolve(test.path); result != test.expected {
+			t.Errorf("%d.resolve(%q) = %d, expected %d", test.compression, test.path, result, test.expected)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	input := []byte(strings.Repeat("Test Content\n", 100))
+	for _, c := range []Compression{Uncompressed, Gzip, Zstd} {
+		compressed, err := c.compress(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := c.compress(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(compressed, again) {
+			t.Errorf("%d: compression is not deterministic", c)
+		}
+		if c != Uncompressed && len(compressed) >= len(input) {
+			t.Errorf("%d: data was not compressed", c)
+		}
+		output, err := c.decompress(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(input, output) {
+			t.Errorf("%d: round trip failed", c)
+		}
+		if empty, err := c.decompress(nil); err != nil || len(empty) != 0 {
+			t.Errorf("%d: unexpected result for empty input: %q, %v", c, empty, err)
+		}
+	}
+}
+
+func TestGzipHeader(t *testing.T) {
+	compressed, err := Gzip.compress([]byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name != "" || !r.ModTime.Equal(time.Time{}) {
+		t.Errorf("Unexpected header: %+v", r.Header)
+	}
+}
@@ -8,61 +8,155 @@ package diff
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"reflect"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
-	"github.com/pmezard/go-difflib/difflib"
+	"github.com/flimzy/diff/engine"
 )
 
 // Result is the result of a diff function. It may be nil, if the inputs were
 // considered identical, or accessed via the String() method to return the
 // diff. Any errors are returned as their textual representation via String()
 // as well.
+//
+// Result implements the error interface, so that it may be returned or
+// reported directly. Errors which prevented the comparison, such as an input
+// of an unsupported type, are available via Err, and may be inspected with
+// errors.Is and errors.As, either on the return value of Err, or on the Result
+// itself.
 type Result struct {
-	diff string
-	err  string
+	opts    *options
+	hunks   []Hunk
+	changes []Change
+	err     error
 }
 
 func (r *Result) String() string {
 	if r == nil {
 		return ""
 	}
-	if r.err != "" {
-		return r.err
+	return r.render(r.opts)
+}
+
+// Render renders the diff as String does, with opts applied in addition to
+// the options with which the comparison was made. Unlike String, Render
+// colorizes the output according to ColorAuto, unless a color mode was
+// chosen with Color. This allows the output format to be chosen after the
+// comparison, as in:
+//
+//	if d := diff.HTTPResponse(expected, actual); d != nil {
+//	    t.Error(d.Render(diff.SideBySide(100), diff.LineNumbers()))
+//	}
+func (r *Result) Render(opts ...Option) string {
+	if r == nil {
+		return ""
+	}
+	o := newOptions(nil)
+	if r.opts != nil {
+		copied := *r.opts
+		o = &copied
+	}
+	if o.color == colorUnset {
+		o.color = ColorAuto
+	}
+	for _, opt := range opts {
+		opt(o)
 	}
-	return string(r.diff)
+	return r.render(o)
 }
 
-// sliceDiff expects two slices of \n-terminated strings to compare.
-func sliceDiff(expected, actual []string) *Result {
-	udiff := difflib.UnifiedDiff{
-		A:        expected,
-		FromFile: "expected",
-		B:        actual,
-		ToFile:   "actual",
-		Context:  2,
-	}
-	diff, err := difflib.GetUnifiedDiffString(udiff)
-	if err != nil {
-		// This can only happen if a write to a byte buffer fails, so can
-		// effectively be ignored, except in case of hardware failure or OOM.
-		panic(err)
+func (r *Result) render(o *options) string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	if o == nil {
+		o = newOptions(nil)
 	}
-	if diff == "" {
+	if o.jsonPointers && len(r.changes) > 0 {
+		return renderChanges(o, r.changes)
+	}
+	if o.sideBySide {
+		return sideBySide(o, r.hunks)
+	}
+	return unified(o, r.hunks)
+}
+
+// Error returns the same value as String.
+func (r *Result) Error() string {
+	return r.String()
+}
+
+// Unwrap returns the error which prevented the comparison, if any.
+func (r *Result) Unwrap() error {
+	return r.Err()
+}
+
+// Hunks returns the hunks that make up the diff. It returns nil if r is nil,
+// or if r represents an error.
+func (r *Result) Hunks() []Hunk {
+	if r == nil {
 		return nil
 	}
-	return &Result{diff: diff}
+	return r.hunks
+}
 
+// Err returns the error which prevented the comparison, if any. A nil error
+// from a non-nil Result means that the inputs were compared successfully, and
+// found to differ.
+func (r *Result) Err() error {
+	if r == nil {
+		return nil
+	}
+	return r.err
+}
+
+// Stats returns a summary of the differences in r.
+func (r *Result) Stats() Stats {
+	var s Stats
+	for _, h := range r.Hunks() {
+		s.Hunks++
+		for _, line := range h.Lines {
+			switch line.Kind {
+			case Insert:
+				s.Insertions++
+			case Delete:
+				s.Deletions++
+			}
+		}
+	}
+	return s
+}
+
+// sliceDiff expects two slices of \n-terminated strings to compare.
+func sliceDiff(expected, actual []string, o *options) *Result {
+	codes := o.algo.opCodes(expected, actual)
+	hunks := buildHunks(expected, actual, engine.Group(codes, o.contextFor(expected, actual)))
+	if len(hunks) == 0 {
+		return nil
+	}
+	return &Result{
+		opts:  o,
+		hunks: hunks,
+	}
 }
 
 // TextSlices compares two slices of text, treating each element as a line of
 // text. Newlines are added to each element, if they are found to be missing.
 func TextSlices(expected, actual []string) *Result {
+	return TextSlicesWith(expected, actual)
+}
+
+// TextSlicesWith is like TextSlices, but accepts options to configure the
+// comparison.
+func TextSlicesWith(expected, actual []string, opts ...Option) *Result {
+	o := newOptions(opts)
+	return textSlices(o.scrubLines(expected), o.scrubLines(actual), o)
+}
+
+func textSlices(expected, actual []string, o *options) *Result {
 	e := make([]string, len(expected))
 	a := make([]string, len(actual))
 	for i, str := range expected {
@@ -71,7 +165,7 @@ func TextSlices(expected, actual []string) *Result {
 	for i, str := range actual {
 		a[i] = strings.TrimRight(str, "\n") + "\n"
 	}
-	return sliceDiff(e, a)
+	return sliceDiff(e, a, o)
 }
 
 // Text compares two strings, line-by-line, for differences.
@@ -80,23 +174,49 @@ func TextSlices(expected, actual []string) *Result {
 // - []byte
 // - io.Reader
 func Text(expected, actual interface{}) *Result {
-	exp, expErr := toText(expected)
+	return TextWith(expected, actual)
+}
+
+// TextWith is like Text, but accepts options to configure the comparison.
+func TextWith(expected, actual interface{}, opts ...Option) *Result {
+	return text(expected, actual, newOptions(opts))
+}
+
+func text(expected, actual interface{}, o *options) *Result {
+	exp, expErr := toText(golden(expected))
 	act, err := toText(actual)
 	if err != nil {
-		return &Result{err: fmt.Sprintf("[diff] actual: %s", err)}
+		return &Result{err: &InputError{Side: SideActual, Err: err}}
 	}
 	var d *Result
 	if expErr != nil {
-		d = &Result{err: fmt.Sprintf("[diff] expected: %s", expErr)}
+		d = &Result{err: &InputError{Side: SideExpected, Err: expErr}}
 	} else {
-		exp = strings.TrimSuffix(exp, "\n")
-		act = strings.TrimSuffix(act, "\n")
-		d = TextSlices(
-			strings.SplitAfter(exp, "\n"),
-			strings.SplitAfter(act, "\n"),
-		)
+		d, act = compare(exp, act, o)
+	}
+	return update(updating(expected), expected, act, d, o)
+}
+
+// compare compares the expected and actual text, after applying any scrubbers
+// and placeholders. It returns the result, and the actual text as compared,
+// including its trailing newline, if any, which is what update mode writes.
+func compare(exp, act string, o *options) (*Result, string) {
+	exp = strings.TrimSuffix(o.scrub(exp), "\n")
+	act = o.scrub(act)
+	var eol string
+	if strings.HasSuffix(act, "\n") {
+		act, eol = act[:len(act)-1], "\n"
+	}
+	act, err := fillPlaceholders(exp, act, o)
+	if err != nil {
+		return &Result{err: err}, act + eol
 	}
-	return update(UpdateMode, expected, act, d)
+	d := textSlices(
+		strings.SplitAfter(exp, "\n"),
+		strings.SplitAfter(act, "\n"),
+		o,
+	)
+	return d, act + eol
 }
 
 func toText(i interface{}) (string, error) {
@@ -105,16 +225,23 @@ func toText(i interface{}) (string, error) {
 		return t, nil
 	case []byte:
 		return string(t), nil
+	case *File:
+		text, err := t.Bytes()
+		return string(text), err
 	case io.Reader:
 		text, err := ioutil.ReadAll(t)
 		return string(text), err
 	case nil:
 		return "", nil
 	}
-	return "", errors.New("input must be of type string, []byte, or io.Reader")
+	return "", &TypeError{Type: reflect.TypeOf(i), Want: "string, []byte, or io.Reader"}
 }
 
 func isJSON(i interface{}) (bool, []byte, error) {
+	if f, ok := i.(*File); ok {
+		buf, err := f.Bytes()
+		return err == nil, buf, err
+	}
 	if r, ok := i.(io.Reader); ok {
 		buf := &bytes.Buffer{}
 		if _, err := buf.ReadFrom(r); err != nil {
@@ -156,59 +283,168 @@ func marshal(i interface{}) ([]byte, error) {
 // object is an io.Reader, it is treated as a JSON stream. If it is a []byte or
 // json.RawMessage, it is treated as raw JSON. Any raw JSON source is
 // unmarshaled then remarshaled with indentation for normalization and
-// comparison.
+// comparison. The expected value may also be a *Merged document.
 func AsJSON(expected, actual interface{}) *Result {
-	expectedJSON, expErr := marshal(expected)
+	return AsJSONWith(expected, actual)
+}
+
+// AsJSONWith is like AsJSON, but accepts options to configure the comparison.
+func AsJSONWith(expected, actual interface{}, opts ...Option) *Result {
+	return asJSON(expected, actual, newOptions(opts))
+}
+
+func asJSON(expected, actual interface{}, o *options) *Result {
+	if m, ok := expected.(*Merged); ok {
+		return m.asJSON(actual, o)
+	}
+	expectedJSON, expErr := marshal(golden(expected))
 	actualJSON, err := marshal(actual)
 	if err != nil {
-		return &Result{err: fmt.Sprintf("failed to marshal actual value: %s", err)}
+		return &Result{err: &MarshalError{Side: SideActual, Op: "marshal", Err: err}}
 	}
 	var d *Result
 	if expErr != nil {
-		d = &Result{err: fmt.Sprintf("failed to marshal expected value: %s", expErr)}
+		d = &Result{err: &MarshalError{Side: SideExpected, Op: "marshal", Err: expErr}}
 	} else {
+		exp, act := o.orderJSON(o.filterJSON(expectedJSON), o.filterJSON(actualJSON))
 		var e, a interface{}
-		_ = json.Unmarshal(expectedJSON, &e)
-		_ = json.Unmarshal(actualJSON, &a)
-		if reflect.DeepEqual(e, a) {
-			return nil
+		_ = json.Unmarshal(exp, &e)
+		_ = json.Unmarshal(act, &a)
+		// Equal documents are still passed to update, so that an unchanged
+		// golden file is recorded, and claimed.
+		if !reflect.DeepEqual(e, a) {
+			var compared string
+			d, compared = compare(string(exp), string(act), o)
+			if d != nil && d.err == nil {
+				// Report the changes between the documents as compared,
+				// after any scrubbers and placeholders, where they remain
+				// valid JSON.
+				var se, sa interface{}
+				if json.Unmarshal([]byte(o.scrub(string(exp))), &se) == nil {
+					e = se
+				}
+				if json.Unmarshal([]byte(compared), &sa) == nil {
+					a = sa
+				}
+				d.changes = jsonChanges(e, a)
+			}
 		}
-		d = Text(string(expectedJSON)+"\n", string(actualJSON)+"\n")
 	}
-	return update(UpdateMode, expected, string(actualJSON), d)
+	if !updating(expected) {
+		return d
+	}
+	if expErr == nil {
+		actualJSON = o.updateJSON(expectedJSON, actualJSON)
+	}
+	return update(true, expected, string(actualJSON), d, o)
+}
+
+// updateJSON returns the actual JSON document to write to a golden file, in
+// update mode. Ignored values are kept, but arrays are ordered, and
+// placeholders filled in, as for the comparison with expected.
+func (o *options) updateJSON(expected, actual []byte) []byte {
+	expected, actual = o.orderJSON(expected, actual)
+	_, act := compare(string(expected), string(actual), o)
+	return []byte(act)
 }
 
 // JSON unmarshals two JSON strings, then calls AsJSON on them. As a special
-// case, empty byte arrays are unmarshaled to nil.
-func JSON(expected, actual []byte) *Result {
-	var expectedInterface, actualInterface interface{}
-	if len(expected) > 0 {
-		if err := json.Unmarshal(expected, &expectedInterface); err != nil {
-			return &Result{err: fmt.Sprintf("failed to unmarshal expected value: %s", err)}
+// case, empty inputs are unmarshaled to nil. expected must be of one of the
+// following types:
+// - []byte
+// - json.RawMessage
+// - io.Reader
+// - *File
+//
+// Unlike AsJSON, which marshals a string as a JSON string value, JSON does not
+// accept a string as expected; convert it to []byte to compare it as raw JSON.
+//
+// In update mode (see Updating), if expected is a *File, it is overwritten
+// with the indented actual JSON when a difference is detected.
+func JSON(expected interface{}, actual []byte) *Result {
+	return JSONWith(expected, actual)
+}
+
+// JSONWith is like JSON, but accepts options to configure the comparison.
+func JSONWith(expected interface{}, actual []byte, opts ...Option) *Result {
+	o := newOptions(opts)
+	actualInterface, err := unmarshalJSON(SideActual, actual)
+	if err != nil {
+		return &Result{err: err}
+	}
+	var d *Result
+	expectedInterface, expErr := unmarshalJSON(SideExpected, golden(expected))
+	if expErr != nil {
+		d = &Result{err: expErr}
+	} else {
+		d = asJSON(expectedInterface, actualInterface, o)
+	}
+	if !updating(expected) {
+		return d
+	}
+	actualJSON, err := marshal(actualInterface)
+	if err != nil {
+		return &Result{err: &MarshalError{Side: SideActual, Op: "marshal", Err: err}}
+	}
+	if expErr == nil {
+		if expectedJSON, e := marshal(expectedInterface); e == nil {
+			actualJSON = o.updateJSON(expectedJSON, actualJSON)
 		}
 	}
-	if len(actual) > 0 {
-		if err := json.Unmarshal(actual, &actualInterface); err != nil {
-			return &Result{err: fmt.Sprintf("failed to unmarshal actual value: %s", err)}
+	return update(true, expected, string(actualJSON), d, o)
+}
+
+// unmarshalJSON reads and unmarshals the raw JSON input i.
+func unmarshalJSON(side string, i interface{}) (interface{}, error) {
+	switch t := i.(type) {
+	case json.RawMessage:
+		i = []byte(t)
+	case string:
+		return nil, &InputError{Side: side, Err: &TypeError{Type: reflect.TypeOf(i), Want: "[]byte, json.RawMessage, io.Reader, or *File"}}
+	}
+	buf, err := toText(i)
+	if err != nil {
+		return nil, &InputError{Side: side, Err: err}
+	}
+	var x interface{}
+	if len(buf) > 0 {
+		if err := json.Unmarshal([]byte(buf), &x); err != nil {
+			return nil, &MarshalError{Side: side, Op: "unmarshal", Err: err}
 		}
 	}
-	return AsJSON(expectedInterface, actualInterface)
+	return x, nil
+}
+
+// spewConfig is the configuration used to dump values for comparison by
+// Interface.
+var spewConfig = spew.ConfigState{
+	Indent:                  "  ",
+	DisableMethods:          true,
+	SortKeys:                true,
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
 }
 
 // Interface compares two objects with reflect.DeepEqual, and if they differ,
-// it returns a diff of the spew.Dump() outputs
+// it returns a diff of the spew.Dump() outputs.
+//
+// If expected is a *File, it is expected to contain the spew.Dump() output of
+// actual. In update mode (see Updating), it is overwritten with the dump of
+// actual when a difference is detected.
 func Interface(expected, actual interface{}) *Result {
+	return InterfaceWith(expected, actual)
+}
+
+// InterfaceWith is like Interface, but accepts options to configure the
+// comparison.
+func InterfaceWith(expected, actual interface{}, opts ...Option) *Result {
+	if f, ok := expected.(*File); ok {
+		return text(f, spewConfig.Sdump(actual), newOptions(opts))
+	}
 	if reflect.DeepEqual(expected, actual) {
 		return nil
 	}
-	scs := spew.ConfigState{
-		Indent:                  "  ",
-		DisableMethods:          true,
-		SortKeys:                true,
-		DisablePointerAddresses: true,
-		DisableCapacities:       true,
-	}
-	expString := scs.Sdump(expected)
-	actString := scs.Sdump(actual)
-	return Text(expString, actString)
+	expString := spewConfig.Sdump(expected)
+	actString := spewConfig.Sdump(actual)
+	return text(expString, actString, newOptions(opts))
 }
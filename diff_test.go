@@ -17,14 +17,82 @@ func TestResultString(t *testing.T) {
 		}
 	})
 	t.Run("diff", func(t *testing.T) {
-		expected := "foo"
-		r := &Result{diff: expected}
+		expected := "--- expected\n+++ actual\n@@ -1 +1 @@\n-foo\n+bar\n"
+		r := &Result{
+			hunks: []Hunk{
+				{
+					OldStart: 1, OldLines: 1,
+					NewStart: 1, NewLines: 1,
+					Lines: []Line{
+						{Kind: Delete, Text: "foo\n"},
+						{Kind: Insert, Text: "bar\n"},
+					},
+				},
+			},
+		}
+		if result := r.String(); result != expected {
+			t.Errorf("Unexpected result: %s", result)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		expected := "some error"
+		r := &Result{err: errors.New(expected)}
 		if result := r.String(); result != expected {
 			t.Errorf("Unexpected result: %s", result)
 		}
 	})
 }
 
+func TestResultAccessors(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var r *Result
+		if r.Hunks() != nil {
+			t.Errorf("Unexpected hunks")
+		}
+		if err := r.Err(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if s := r.Stats(); s != (Stats{}) {
+			t.Errorf("Unexpected stats: %+v", s)
+		}
+	})
+	t.Run("diff", func(t *testing.T) {
+		r := Text("foo\nbar\nbaz\nqux\n", "foo\nbaz\nquux\nqux\nzot\n")
+		if err := r.Err(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		expected := []Hunk{
+			{
+				OldStart: 1, OldLines: 4,
+				NewStart: 1, NewLines: 5,
+				Lines: []Line{
+					{Kind: Context, Text: "foo\n"},
+					{Kind: Delete, Text: "bar\n"},
+					{Kind: Context, Text: "baz\n"},
+					{Kind: Insert, Text: "quux\n"},
+					{Kind: Context, Text: "qux\n"},
+					{Kind: Insert, Text: "zot\n"},
+				},
+			},
+		}
+		if d := Interface(expected, r.Hunks()); d != nil {
+			t.Errorf("Unexpected hunks:\n%s\n", d)
+		}
+		if d := Interface(Stats{Hunks: 1, Insertions: 2, Deletions: 1}, r.Stats()); d != nil {
+			t.Errorf("Unexpected stats:\n%s\n", d)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		r := Text(123, "foo")
+		if r.Hunks() != nil {
+			t.Errorf("Unexpected hunks")
+		}
+		if r.Err() == nil {
+			t.Errorf("Expected an error")
+		}
+	})
+}
+
 func TestSliceDiff(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -44,7 +112,7 @@ func TestSliceDiff(t *testing.T) {
 		},
 	}
 	for _, test := range tests {
-		result := sliceDiff(test.expected, test.actual)
+		result := sliceDiff(test.expected, test.actual, newOptions(nil))
 		var resultText string
 		if result != nil {
 			resultText = result.String()
@@ -110,13 +178,13 @@ func TestText(t *testing.T) {
 		{
 			name:     "invalid exp type",
 			expected: 123,
-			result:   "[diff] expected: input must be of type string, []byte, or io.Reader",
+			result:   "[diff] expected: input must be of type string, []byte, or io.Reader, not int",
 		},
 		{
 			name:     "invalid act type",
 			expected: "123",
 			actual:   123,
-			result:   "[diff] actual: input must be of type string, []byte, or io.Reader",
+			result:   "[diff] actual: input must be of type string, []byte, or io.Reader, not int",
 		},
 		{
 			name:     "string vs io.Reader",
@@ -394,3 +462,16 @@ func TestInterface(t *testing.T) {
 		}
 	}
 }
+
+func TestInterfaceFile(t *testing.T) {
+	golden := &File{Path: "testdata/interface.golden"}
+	if Updating(golden.Path) {
+		t.Skip("golden file would be overwritten in update mode")
+	}
+	if d := Interface(golden, map[string]int{"foo": 2}); d == nil {
+		t.Error("Expected a difference")
+	}
+	if d := Interface(&File{Path: golden.Path}, map[string]int{"foo": 1}); d != nil {
+		t.Error(d)
+	}
+}
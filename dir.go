@@ -6,33 +6,57 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"syscall"
 )
 
 // DirChecksum compares the checksum of the contents of dir against the checksums
-// in expected. Expected should be a map of all files expected in the directory,
-// with the full path and filename as key, and the md5 sum as the value.
-func DirChecksum(expected map[string]string, dir string) *Result {
+// in expected. Expected should be a map[string]string of all files expected in
+// the directory, with the full path and filename as key, and the md5 sum as the
+// value.
+//
+// Expected may also be a *File containing a manifest of the directory, which
+// is the spew.Dump() output of such a map, as compared by Interface. In update
+// mode (see Updating), the manifest is overwritten when a difference is
+// detected.
+func DirChecksum(expected interface{}, dir string) *Result {
+	if err := checkManifest(expected); err != nil {
+		return &Result{err: err}
+	}
 	actual, err := checkDir(dir, false)
 	if err != nil {
-		return &Result{err: err.Error()}
+		return &Result{err: err}
 	}
 	return Interface(expected, actual)
 }
 
 // DirFullCheck compares the checksum of the contents of dir against the checksums
-// in expected. Expected should be a map of all files expected in the directory,
-// with the full path and filename as key, and the md5 sum, mode, and ownership
-// as the value.
-func DirFullCheck(expected map[string]string, dir string) *Result {
+// in expected. Expected should be a map[string]string of all files expected in
+// the directory, with the full path and filename as key, and the md5 sum, mode,
+// and ownership as the value. As with DirChecksum, expected may also be a
+// manifest *File.
+func DirFullCheck(expected interface{}, dir string) *Result {
+	if err := checkManifest(expected); err != nil {
+		return &Result{err: err}
+	}
 	actual, err := checkDir(dir, true)
 	if err != nil {
-		return &Result{err: err.Error()}
+		return &Result{err: err}
 	}
 	return Interface(expected, actual)
 }
 
+// checkManifest returns an error if expected is neither a map[string]string
+// nor a manifest *File.
+func checkManifest(expected interface{}) error {
+	switch expected.(type) {
+	case nil, map[string]string, *File:
+		return nil
+	}
+	return &InputError{Side: SideExpected, Err: &TypeError{Type: reflect.TypeOf(expected), Want: "map[string]string or *File"}}
+}
+
 func checkDir(dir string, full bool) (map[string]string, error) {
 	result := make(map[string]string)
 	err := recurseDir(result, full, []string{dir})
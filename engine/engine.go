@@ -0,0 +1,140 @@
+// Package engine implements line-oriented diff algorithms. Each algorithm
+// compares two sequences of strings, and returns a list of OpCodes describing
+// how to transform the first into the second.
+package engine
+
+// OpCode describes how to turn a[I1:I2] into b[J1:J2]. Tag is one of:
+//
+//	'r' (replace):  a[I1:I2] should be replaced by b[J1:J2]
+//	'd' (delete):   a[I1:I2] should be deleted; J1 == J2 in this case
+//	'i' (insert):   b[J1:J2] should be inserted at a[I1:I1]; I1 == I2 in this case
+//	'e' (equal):    a[I1:I2] == b[J1:J2]
+//
+// These semantics match those of difflib.OpCode.
+type OpCode struct {
+	Tag    byte
+	I1, I2 int
+	J1, J2 int
+}
+
+// pair is a matched pair of indexes into a and b, respectively.
+type pair struct {
+	i, j int
+}
+
+// env holds the state of a single comparison.
+type env struct {
+	a, b  []string
+	pairs []pair
+}
+
+func (e *env) match(i, j int) {
+	e.pairs = append(e.pairs, pair{i, j})
+}
+
+// trim records the common prefix and counts the common suffix of the given
+// ranges. It returns the new range boundaries, excluding the suffix, and the
+// length of the suffix, which the caller must record with matchSuffix once
+// the remainder of the range has been processed.
+func (e *env) trim(a0, a1, b0, b1 int) (int, int, int, int, int) {
+	for a0 < a1 && b0 < b1 && e.a[a0] == e.b[b0] {
+		e.match(a0, b0)
+		a0++
+		b0++
+	}
+	var s int
+	for a1-s > a0 && b1-s > b0 && e.a[a1-s-1] == e.b[b1-s-1] {
+		s++
+	}
+	return a0, a1 - s, b0, b1 - s, s
+}
+
+func (e *env) matchSuffix(a1, b1, s int) {
+	for i := 0; i < s; i++ {
+		e.match(a1+i, b1+i)
+	}
+}
+
+// opCodes converts the matched pairs of e into OpCodes.
+func (e *env) opCodes() []OpCode {
+	var codes []OpCode
+	var i, j int
+	pairs := append(e.pairs, pair{len(e.a), len(e.b)})
+	for n, p := range pairs {
+		if p.i > i || p.j > j {
+			tag := byte('r')
+			switch {
+			case p.j == j:
+				tag = 'd'
+			case p.i == i:
+				tag = 'i'
+			}
+			codes = append(codes, OpCode{Tag: tag, I1: i, I2: p.i, J1: j, J2: p.j})
+		}
+		if n == len(pairs)-1 {
+			break
+		}
+		if last := len(codes) - 1; last >= 0 && codes[last].Tag == 'e' && codes[last].I2 == p.i && codes[last].J2 == p.j {
+			codes[last].I2++
+			codes[last].J2++
+		} else {
+			codes = append(codes, OpCode{Tag: 'e', I1: p.i, I2: p.i + 1, J1: p.j, J2: p.j + 1})
+		}
+		i, j = p.i+1, p.j+1
+	}
+	return codes
+}
+
+// Group isolates change clusters by eliminating ranges with no changes, and
+// returns groups of OpCodes with up to n lines of context. Each group is
+// suitable for rendering as a single hunk of a unified diff. The behavior
+// matches that of difflib.SequenceMatcher.GetGroupedOpCodes.
+func Group(codes []OpCode, n int) [][]OpCode {
+	if n < 0 {
+		n = 3
+	}
+	codes = append([]OpCode(nil), codes...)
+	if len(codes) == 0 {
+		codes = []OpCode{{'e', 0, 1, 0, 1}}
+	}
+	// Fixup leading and trailing groups if they show no changes.
+	if c := codes[0]; c.Tag == 'e' {
+		codes[0] = OpCode{c.Tag, max(c.I1, c.I2-n), c.I2, max(c.J1, c.J2-n), c.J2}
+	}
+	if c := codes[len(codes)-1]; c.Tag == 'e' {
+		codes[len(codes)-1] = OpCode{c.Tag, c.I1, min(c.I2, c.I1+n), c.J1, min(c.J2, c.J1+n)}
+	}
+	nn := n + n
+	var groups [][]OpCode
+	var group []OpCode
+	for _, c := range codes {
+		i1, i2, j1, j2 := c.I1, c.I2, c.J1, c.J2
+		// End the current group and start a new one whenever there is a
+		// large range with no changes.
+		if c.Tag == 'e' && i2-i1 > nn {
+			group = append(group, OpCode{c.Tag, i1, min(i2, i1+n), j1, min(j2, j1+n)})
+			groups = append(groups, group)
+			group = nil
+			i1, j1 = max(i1, i2-n), max(j1, j2-n)
+		}
+		group = append(group, OpCode{c.Tag, i1, i2, j1, j2})
+	}
+	if len(group) > 0 && !(len(group) == 1 && group[0].Tag == 'e') {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
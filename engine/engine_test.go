@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var algorithms = map[string]func(a, b []string) []OpCode{
+	"Myers":     Myers,
+	"Patience":  Patience,
+	"Histogram": Histogram,
+}
+
+// apply reconstructs b from a and codes, verifying that the codes are
+// contiguous and that equal ranges are in fact equal.
+func apply(t *testing.T, a, b []string, codes []OpCode) {
+	t.Helper()
+	var i, j int
+	var result []string
+	for _, c := range codes {
+		if c.I1 != i || c.J1 != j {
+			t.Fatalf("Non-contiguous opcode %c %d:%d %d:%d", c.Tag, c.I1, c.I2, c.J1, c.J2)
+		}
+		switch c.Tag {
+		case 'e':
+			if !reflect.DeepEqual(a[c.I1:c.I2], b[c.J1:c.J2]) {
+				t.Fatalf("Unequal 'e' range %d:%d %d:%d", c.I1, c.I2, c.J1, c.J2)
+			}
+			result = append(result, a[c.I1:c.I2]...)
+		case 'd':
+			if c.J1 != c.J2 {
+				t.Fatalf("Invalid 'd' range %d:%d", c.J1, c.J2)
+			}
+		case 'i', 'r':
+			result = append(result, b[c.J1:c.J2]...)
+		default:
+			t.Fatalf("Unknown tag %c", c.Tag)
+		}
+		i, j = c.I2, c.J2
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("Opcodes end at %d,%d, expected %d,%d", i, j, len(a), len(b))
+	}
+	if len(result) == 0 && len(b) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(result, b) {
+		t.Fatalf("Reconstructed %q, expected %q", result, b)
+	}
+}
+
+func matched(codes []OpCode) int {
+	var n int
+	for _, c := range codes {
+		if c.Tag == 'e' {
+			n += c.I2 - c.I1
+		}
+	}
+	return n
+}
+
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func randomLines(r *rand.Rand, n int, alphabet string) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = string(alphabet[r.Intn(len(alphabet))])
+	}
+	return lines
+}
+
+func TestAlgorithms(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []OpCode
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name: "insert into empty",
+			b:    []string{"a", "b"},
+			want: []OpCode{{'i', 0, 0, 0, 2}},
+		},
+		{
+			name: "delete all",
+			a:    []string{"a", "b"},
+			want: []OpCode{{'d', 0, 2, 0, 0}},
+		},
+		{
+			name: "equal",
+			a:    []string{"a", "b"},
+			b:    []string{"a", "b"},
+			want: []OpCode{{'e', 0, 2, 0, 2}},
+		},
+		{
+			name: "replace middle",
+			a:    []string{"a", "b", "c"},
+			b:    []string{"a", "x", "c"},
+			want: []OpCode{{'e', 0, 1, 0, 1}, {'r', 1, 2, 1, 2}, {'e', 2, 3, 2, 3}},
+		},
+	}
+	for name, algo := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			for _, test := range tests {
+				t.Run(test.name, func(t *testing.T) {
+					codes := algo(test.a, test.b)
+					apply(t, test.a, test.b, codes)
+					if !reflect.DeepEqual(test.want, codes) {
+						t.Errorf("Unexpected result: %v", codes)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 500; n++ {
+		a := randomLines(r, r.Intn(40), "abcdef")
+		b := randomLines(r, r.Intn(40), "abcdef")
+		for name, algo := range algorithms {
+			codes := algo(a, b)
+			apply(t, a, b, codes)
+			if name != "Myers" {
+				continue
+			}
+			if got, want := matched(codes), lcsLength(a, b); got != want {
+				t.Fatalf("Myers matched %d lines, LCS is %d\na: %q\nb: %q", got, want, a, b)
+			}
+		}
+	}
+}
+
+func TestPatienceAnchors(t *testing.T) {
+	// A moved function body is the classic case where patience diff, which
+	// anchors on the unique "func" lines, outperforms a minimal diff.
+	a := strings.Split("func a() {\n}\n\nfunc b() {\n}\n", "\n")
+	b := strings.Split("func b() {\n}\n\nfunc a() {\n}\n", "\n")
+	codes := Patience(a, b)
+	apply(t, a, b, codes)
+}
+
+func TestHistogramRepetitive(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 200; i++ {
+		a = append(a, "{", "  x", "}")
+		b = append(b, "{", "  x", "}")
+	}
+	b[300] = "  y"
+	codes := Histogram(a, b)
+	apply(t, a, b, codes)
+	want := []OpCode{{'e', 0, 300, 0, 300}, {'r', 300, 301, 300, 301}, {'e', 301, 600, 301, 600}}
+	if !reflect.DeepEqual(want, codes) {
+		t.Errorf("Unexpected result: %v", codes)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for n := 0; n < 200; n++ {
+		a := randomLines(r, r.Intn(60), "abcdefghijklmnop")
+		b := append([]string(nil), a...)
+		for k := 0; k < 3 && len(b) > 0; k++ {
+			b[r.Intn(len(b))] = "x"
+		}
+		m := difflib.NewMatcher(a, b)
+		var codes []OpCode
+		for _, c := range m.GetOpCodes() {
+			codes = append(codes, OpCode{c.Tag, c.I1, c.I2, c.J1, c.J2})
+		}
+		for _, context := range []int{0, 2, 3} {
+			var want [][]OpCode
+			// GetGroupedOpCodes modifies the matcher's cached opcodes, so a
+			// fresh matcher is needed for each call.
+			for _, g := range difflib.NewMatcher(a, b).GetGroupedOpCodes(context) {
+				var group []OpCode
+				for _, c := range g {
+					group = append(group, OpCode{c.Tag, c.I1, c.I2, c.J1, c.J2})
+				}
+				want = append(want, group)
+			}
+			if got := Group(codes, context); !reflect.DeepEqual(want, got) {
+				t.Fatalf("Unexpected groups for context %d:\n%v\nexpected:\n%v", context, got, want)
+			}
+		}
+	}
+}
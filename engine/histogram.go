@@ -0,0 +1,74 @@
+package engine
+
+// maxChainLength is the maximum number of occurrences of a line for it to be
+// considered as an anchor by the histogram algorithm. Regions in which every
+// line occurs more often are compared with Myers' algorithm instead.
+const maxChainLength = 64
+
+// Histogram compares a and b using the histogram diff algorithm, as found in
+// JGit and Git. It extends the patience algorithm by anchoring on the common
+// region containing the least frequently occurring lines, rather than only on
+// unique lines, which produces readable results on input with many repeated
+// lines, such as indented data dumps.
+func Histogram(a, b []string) []OpCode {
+	e := &env{a: a, b: b}
+	e.histogram(0, len(a), 0, len(b))
+	return e.opCodes()
+}
+
+func (e *env) histogram(a0, a1, b0, b1 int) {
+	a0, a1, b0, b1, s := e.trim(a0, a1, b0, b1)
+	if a0 < a1 && b0 < b1 {
+		if x0, y0, x1, y1, ok := e.lowestRegion(a0, a1, b0, b1); ok {
+			e.histogram(a0, x0, b0, y0)
+			for i := 0; i < x1-x0; i++ {
+				e.match(x0+i, y0+i)
+			}
+			e.histogram(x1, a1, y1, b1)
+		} else {
+			e.myers(a0, a1, b0, b1)
+		}
+	}
+	e.matchSuffix(a1, b1, s)
+}
+
+// lowestRegion finds the longest common region of a[a0:a1] and b[b0:b1]
+// whose least frequent line occurs the fewest times in a[a0:a1].
+func (e *env) lowestRegion(a0, a1, b0, b1 int) (x0, y0, x1, y1 int, ok bool) {
+	index := make(map[string][]int)
+	for i := a0; i < a1; i++ {
+		index[e.a[i]] = append(index[e.a[i]], i)
+	}
+	bestCount := maxChainLength + 1
+	var bestLen int
+	for j := b0; j < b1; j++ {
+		positions := index[e.b[j]]
+		if len(positions) == 0 || len(positions) > bestCount {
+			continue
+		}
+		for _, i := range positions {
+			si, sj := i, j
+			for si > a0 && sj > b0 && e.a[si-1] == e.b[sj-1] {
+				si--
+				sj--
+			}
+			ei, ej := i+1, j+1
+			for ei < a1 && ej < b1 && e.a[ei] == e.b[ej] {
+				ei++
+				ej++
+			}
+			count := len(positions)
+			for k := si; k < ei; k++ {
+				if c := len(index[e.a[k]]); c < count {
+					count = c
+				}
+			}
+			if count < bestCount || (count == bestCount && ei-si > bestLen) {
+				x0, y0, x1, y1 = si, sj, ei, ej
+				bestCount, bestLen = count, ei-si
+				ok = true
+			}
+		}
+	}
+	return x0, y0, x1, y1, ok
+}
@@ -0,0 +1,81 @@
+package engine
+
+// Myers compares a and b using Myers' O(ND) difference algorithm, in its
+// linear space variant. The result is a minimal edit script.
+func Myers(a, b []string) []OpCode {
+	e := &env{a: a, b: b}
+	e.myers(0, len(a), 0, len(b))
+	return e.opCodes()
+}
+
+// myers records the matches between a[a0:a1] and b[b0:b1].
+func (e *env) myers(a0, a1, b0, b1 int) {
+	a0, a1, b0, b1, s := e.trim(a0, a1, b0, b1)
+	if a0 < a1 && b0 < b1 {
+		x0, y0, x1, y1 := e.middleSnake(a0, a1, b0, b1)
+		e.myers(a0, x0, b0, y0)
+		for i := 0; i < x1-x0; i++ {
+			e.match(x0+i, y0+i)
+		}
+		e.myers(x1, a1, y1, b1)
+	}
+	e.matchSuffix(a1, b1, s)
+}
+
+// middleSnake finds the middle snake of an optimal path through the edit
+// graph of a[a0:a1] and b[b0:b1], by searching simultaneously forward from
+// the start, and backward from the end, until the two searches overlap. It
+// returns the start and end points of the snake.
+func (e *env) middleSnake(a0, a1, b0, b1 int) (x0, y0, x1, y1 int) {
+	n, m := a1-a0, b1-b0
+	limit := (n + m + 1) / 2
+	delta := n - m
+	odd := delta%2 != 0
+	off := limit + 1
+	// vf[off+k] is the furthest x reached on diagonal k searching forward;
+	// vb[off+k] is the furthest distance from the end reached on diagonal k
+	// searching backward.
+	vf := make([]int, 2*limit+3)
+	vb := make([]int, 2*limit+3)
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && e.a[a0+x] == e.b[b0+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 && x+vb[off+kb] >= n {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && e.a[a1-1-x] == e.b[b1-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+			if kf := delta - k; !odd && kf >= -d && kf <= d && x+vf[off+kf] >= n {
+				return a0 + n - x, b0 + m - y, a0 + n - sx, b0 + m - sy
+			}
+		}
+	}
+	// Unreachable for non-empty inputs, but fall back to treating the entire
+	// range as a replacement.
+	return a0, b0, a0, b0
+}
@@ -0,0 +1,95 @@
+package engine
+
+import "sort"
+
+// Patience compares a and b using the patience diff algorithm. Lines which
+// occur exactly once in each input are used as anchors, and the regions
+// between anchors are compared recursively. Regions without unique lines are
+// compared with Myers' algorithm.
+func Patience(a, b []string) []OpCode {
+	e := &env{a: a, b: b}
+	e.patience(0, len(a), 0, len(b))
+	return e.opCodes()
+}
+
+func (e *env) patience(a0, a1, b0, b1 int) {
+	a0, a1, b0, b1, s := e.trim(a0, a1, b0, b1)
+	if a0 < a1 && b0 < b1 {
+		anchors := e.uniqueLCS(a0, a1, b0, b1)
+		if len(anchors) == 0 {
+			e.myers(a0, a1, b0, b1)
+		} else {
+			i, j := a0, b0
+			for _, p := range anchors {
+				e.patience(i, p.i, j, p.j)
+				e.match(p.i, p.j)
+				i, j = p.i+1, p.j+1
+			}
+			e.patience(i, a1, j, b1)
+		}
+	}
+	e.matchSuffix(a1, b1, s)
+}
+
+// uniqueLCS returns the longest common subsequence of the lines which occur
+// exactly once in each of a[a0:a1] and b[b0:b1].
+func (e *env) uniqueLCS(a0, a1, b0, b1 int) []pair {
+	type occurrence struct {
+		countA, countB int
+		i, j           int
+	}
+	lines := make(map[string]*occurrence)
+	for i := a0; i < a1; i++ {
+		o, ok := lines[e.a[i]]
+		if !ok {
+			o = &occurrence{}
+			lines[e.a[i]] = o
+		}
+		o.countA++
+		o.i = i
+	}
+	for j := b0; j < b1; j++ {
+		if o, ok := lines[e.b[j]]; ok {
+			o.countB++
+			o.j = j
+		}
+	}
+	var uniques []pair
+	for _, o := range lines {
+		if o.countA == 1 && o.countB == 1 {
+			uniques = append(uniques, pair{o.i, o.j})
+		}
+	}
+	sort.Slice(uniques, func(x, y int) bool { return uniques[x].i < uniques[y].i })
+	return longestIncreasing(uniques)
+}
+
+// longestIncreasing returns the longest subsequence of pairs, which are
+// ordered by i, that is also increasing in j. It uses patience sorting.
+func longestIncreasing(pairs []pair) []pair {
+	if len(pairs) == 0 {
+		return nil
+	}
+	// tops[n] is the index into pairs of the top card of pile n; prev links
+	// each card to the top of the previous pile at the time it was placed.
+	var tops []int
+	prev := make([]int, len(pairs))
+	for x, p := range pairs {
+		n := sort.Search(len(tops), func(n int) bool { return pairs[tops[n]].j > p.j })
+		if n > 0 {
+			prev[x] = tops[n-1]
+		} else {
+			prev[x] = -1
+		}
+		if n == len(tops) {
+			tops = append(tops, x)
+		} else {
+			tops[n] = x
+		}
+	}
+	result := make([]pair, len(tops))
+	for x, n := tops[len(tops)-1], len(tops)-1; n >= 0; x, n = prev[x], n-1 {
+		result[n] = pairs[x]
+	}
+	return result
+}
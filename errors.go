@@ -0,0 +1,154 @@
+package diff
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+)
+
+// Sides of a comparison, as reported by the error types in this package.
+const (
+	SideExpected = "expected"
+	SideActual   = "actual"
+)
+
+// TypeError is returned when an input value is of a type which cannot be
+// compared by the called function.
+type TypeError struct {
+	// Type is the type of the offending value, or nil for an untyped nil.
+	Type reflect.Type
+	// Want describes the supported types.
+	Want string
+}
+
+func (e *TypeError) Error() string {
+	if e.Type == nil {
+		return "input must be of type " + e.Want + ", not nil"
+	}
+	return "input must be of type " + e.Want + ", not " + e.Type.String()
+}
+
+// InputError is returned when an input value to a text comparison could not
+// be read, or is of an unsupported type.
+type InputError struct {
+	// Side is either SideExpected or SideActual.
+	Side string
+	Err  error
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("[diff] %s: %s", e.Side, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *InputError) Unwrap() error { return e.Err }
+
+// MarshalError is returned when an input value cannot be marshaled to, or
+// unmarshaled from, JSON.
+type MarshalError struct {
+	// Side is either SideExpected or SideActual.
+	Side string
+	// Op is either "marshal" or "unmarshal".
+	Op  string
+	Err error
+}
+
+func (e *MarshalError) Error() string {
+	return fmt.Sprintf("failed to %s %s value: %s", e.Op, e.Side, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *MarshalError) Unwrap() error { return e.Err }
+
+// DumpError is returned when an HTTP request or response cannot be read or
+// dumped for comparison.
+type DumpError struct {
+	// Side is either SideExpected or SideActual.
+	Side string
+	// Kind is either "request" or "response".
+	Kind string
+	Err  error
+}
+
+func (e *DumpError) Error() string {
+	return fmt.Sprintf("Failed to dump %s %s: %s", e.Side, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DumpError) Unwrap() error { return e.Err }
+
+// UpdateError is returned when a golden file cannot be written in update mode.
+type UpdateError struct {
+	Path string
+	Err  error
+}
+
+func (e *UpdateError) Error() string {
+	return fmt.Sprintf("Update failed: %s", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *UpdateError) Unwrap() error { return e.Err }
+
+// SectionError is returned when a section of a txtar archive is not found.
+// It matches fs.ErrNotExist, as a missing file would.
+type SectionError struct {
+	Path    string
+	Section string
+}
+
+func (e *SectionError) Error() string {
+	return fmt.Sprintf("section %q not found in %s", e.Section, e.Path)
+}
+
+// Is reports whether target is fs.ErrNotExist.
+func (e *SectionError) Is(target error) bool { return target == fs.ErrNotExist }
+
+// PartialRunError is returned by RemoveStaleGoldens when tests were selected
+// or skipped with the named test flag, in which case golden files which were
+// not read may belong to tests which did not run, and are not removed.
+type PartialRunError struct {
+	// Flag is the name of the flag, such as "run", without the "test." prefix.
+	Flag string
+}
+
+func (e *PartialRunError) Error() string {
+	return "not removing stale golden files, as tests were filtered with -" + e.Flag
+}
+
+// PlaceholderError is returned when a placeholder in the expected value of a
+// comparison is invalid, such as a regex placeholder whose expression cannot
+// be compiled. See Placeholders.
+type PlaceholderError struct {
+	// Placeholder is the placeholder as written, such as `{{regex "a("}}`.
+	Placeholder string
+	Err         error
+}
+
+func (e *PlaceholderError) Error() string {
+	return fmt.Sprintf("invalid placeholder %s: %s", e.Placeholder, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PlaceholderError) Unwrap() error { return e.Err }
+
+// ConflictError is returned in update mode when two comparisons in the same
+// test run would write different actual values to the same golden file. It is
+// wrapped in an *UpdateError.
+type ConflictError struct {
+	Path string
+	// Test is the name of the test whose update was rejected, and OtherTest
+	// the name of the test which previously set the file's content, if known.
+	Test, OtherTest string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting updates to %s by %s and %s", e.Path, testName(e.OtherTest), testName(e.Test))
+}
+
+func testName(name string) string {
+	if name == "" {
+		return "unknown test"
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestResultErrors(t *testing.T) {
+	t.Run("diff is not an error", func(t *testing.T) {
+		r := Text("foo", "bar")
+		if err := r.Err(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if r.Error() != r.String() {
+			t.Errorf("Error() and String() differ")
+		}
+	})
+	t.Run("input type", func(t *testing.T) {
+		r := Text(123, "foo")
+		var inputErr *InputError
+		if !errors.As(r, &inputErr) {
+			t.Fatalf("Expected *InputError, got %T", r.Err())
+		}
+		if inputErr.Side != SideExpected {
+			t.Errorf("Unexpected side: %s", inputErr.Side)
+		}
+		var typeErr *TypeError
+		if !errors.As(r.Err(), &typeErr) {
+			t.Fatalf("Expected *TypeError")
+		}
+		if typeErr.Type != reflect.TypeOf(123) {
+			t.Errorf("Unexpected type: %v", typeErr.Type)
+		}
+	})
+	t.Run("read error", func(t *testing.T) {
+		r := Text("foo", &File{Path: "testdata/not_found"})
+		if !errors.Is(r, os.ErrNotExist) {
+			t.Errorf("Expected os.ErrNotExist, got: %s", r)
+		}
+	})
+	t.Run("marshal", func(t *testing.T) {
+		r := AsJSON("foo", make(chan int))
+		var marshalErr *MarshalError
+		if !errors.As(r, &marshalErr) {
+			t.Fatalf("Expected *MarshalError, got %T", r.Err())
+		}
+		if marshalErr.Side != SideActual || marshalErr.Op != "marshal" {
+			t.Errorf("Unexpected error: %+v", marshalErr)
+		}
+	})
+	t.Run("unmarshal", func(t *testing.T) {
+		r := JSON([]byte("invalid json"), []byte("{}"))
+		var marshalErr *MarshalError
+		if !errors.As(r, &marshalErr) {
+			t.Fatalf("Expected *MarshalError, got %T", r.Err())
+		}
+		if marshalErr.Side != SideExpected || marshalErr.Op != "unmarshal" {
+			t.Errorf("Unexpected error: %+v", marshalErr)
+		}
+	})
+	t.Run("json string", func(t *testing.T) {
+		r := JSON(`{}`, []byte("{}"))
+		var typeErr *TypeError
+		if !errors.As(r, &typeErr) {
+			t.Fatalf("Expected *TypeError, got %T", r.Err())
+		}
+		if typeErr.Type != reflect.TypeOf("") {
+			t.Errorf("Unexpected type: %v", typeErr.Type)
+		}
+	})
+	t.Run("http", func(t *testing.T) {
+		r := HTTPResponse(nil, 123)
+		var dumpErr *DumpError
+		if !errors.As(r, &dumpErr) {
+			t.Fatalf("Expected *DumpError, got %T", r.Err())
+		}
+		if dumpErr.Side != SideActual || dumpErr.Kind != "response" {
+			t.Errorf("Unexpected error: %+v", dumpErr)
+		}
+		var typeErr *TypeError
+		if !errors.As(r, &typeErr) {
+			t.Errorf("Expected *TypeError")
+		}
+	})
+	t.Run("dir", func(t *testing.T) {
+		r := DirChecksum(nil, "testdata/not_found")
+		if !errors.Is(r, os.ErrNotExist) {
+			t.Errorf("Expected os.ErrNotExist, got: %s", r)
+		}
+	})
+	t.Run("dir manifest type", func(t *testing.T) {
+		r := DirChecksum([]string{"foo"}, "testdata")
+		var typeErr *TypeError
+		if !errors.As(r, &typeErr) {
+			t.Fatalf("Expected *TypeError, got %T", r.Err())
+		}
+		if typeErr.Type != reflect.TypeOf([]string{}) {
+			t.Errorf("Unexpected type: %v", typeErr.Type)
+		}
+	})
+}
@@ -1,32 +1,189 @@
 package diff
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"io/fs"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sync"
 )
 
 // File converts a file into an io.Reader.
-// When UpdateMode is true, a dected difference will cause File to be
-// overwritten with the actual value, when File is the expected value.
+// In update mode (see Updating), a detected difference will cause File to be
+// overwritten with the actual value, when File is the expected value. Parallel
+// tests may share a golden file, but in update mode, all comparisons against
+// it during a test run must agree on its content; see ConflictError.
+//
+// A File may be used in any number of comparisons, each of which reads the
+// file afresh. A missing file is an error, except in update mode, where it is
+// treated as empty, so that it is created with the actual value.
 type File struct {
 	Path string
-	r    io.Reader
-	done bool
+	// CreateDirs causes any missing parent directories of Path to be created
+	// when the file is written in update mode.
+	CreateDirs bool
+	// FS, if set, is the file system from which the file is read, such as an
+	// embed.FS. Path must then be a valid fs.FS path, as checked by
+	// fs.ValidPath.
+	FS fs.FS
+	// Root is the directory, on disk, to which Path is relative when the file
+	// is written in update mode, or read without an FS. It defaults to the
+	// working directory, which for an embed.FS declared in a test is the
+	// package directory. Updates are only visible through FS once it is
+	// rebuilt or reloaded.
+	Root string
+	// Section, if set, is the name of a section of the txtar archive at Path,
+	// which is then read and, in update mode, written in place of the entire
+	// file. Other sections of the archive are left intact. See Section.
+	Section string
+	// Compression selects how the file is compressed. By default, it is
+	// detected by the file's extension, so that "foo.json.gz" is read and
+	// written with gzip compression. Comparisons, and any Section, apply to
+	// the decompressed content.
+	Compression Compression
+
+	mu sync.Mutex
+	r  *bytes.Reader
 }
 
 var _ io.Reader = &File{}
 
+// Read reads the content of the file. The file is read in full and closed by
+// the first call, and the content then returned by subsequent calls. Once Read
+// has returned io.EOF, the next call reads the file again from the beginning.
 func (f *File) Read(p []byte) (int, error) {
-	if f.done {
-		return 0, io.EOF
-	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if f.r == nil {
-		var err error
-		if f.r, err = os.Open(f.Path); err != nil {
+		content, err := f.Bytes()
+		if err != nil {
 			return 0, err
 		}
+		f.r = bytes.NewReader(content)
 	}
 	n, err := f.r.Read(p)
-	f.done = err != nil
+	if err == io.EOF {
+		f.r = nil
+	}
 	return n, err
 }
+
+// Section returns a File which addresses the named section of the txtar
+// archive at path. Sections are introduced by marker lines of the form
+// "-- name --", as described by golang.org/x/tools/txtar. For example:
+//
+//	diff.HTTPResponse(diff.Section("testdata/foo.txtar", "response"), resp)
+func Section(path, name string) *File {
+	return &File{Path: path, Section: name}
+}
+
+// Bytes returns the content of the file.
+func (f *File) Bytes() ([]byte, error) {
+	goldens.recordRead(f.diskPath())
+	return f.read()
+}
+
+// read returns the content of the file, without recording the read for
+// StaleGoldens.
+func (f *File) read() ([]byte, error) {
+	path := f.diskPath()
+	defer goldens.lock(path)()
+	var raw []byte
+	var err error
+	if f.FS != nil {
+		raw, err = fs.ReadFile(f.FS, f.Path)
+	} else {
+		raw, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f.decode(raw)
+}
+
+// Exists reports whether the file, or section, exists. Checking for existence
+// does not count as a read for StaleGoldens.
+func (f *File) Exists() bool {
+	if f.Section != "" {
+		_, err := f.read()
+		return err == nil
+	}
+	var err error
+	if f.FS != nil {
+		_, err = fs.Stat(f.FS, f.Path)
+	} else {
+		_, err = os.Stat(f.diskPath())
+	}
+	return err == nil
+}
+
+// decode returns the content of the file, given its raw content.
+func (f *File) decode(raw []byte) ([]byte, error) {
+	data, err := f.Compression.resolve(f.Path).decompress(raw)
+	if err != nil {
+		return nil, err
+	}
+	if f.Section == "" {
+		return data, nil
+	}
+	data, ok := parseArchive(data).get(f.Section)
+	if !ok {
+		return nil, &SectionError{Path: f.Path, Section: f.Section}
+	}
+	return data, nil
+}
+
+// encode returns the raw content of the file with the given content, where
+// old is its existing raw content, if any.
+func (f *File) encode(old, content []byte) ([]byte, error) {
+	c := f.Compression.resolve(f.Path)
+	if f.Section != "" {
+		data, err := c.decompress(old)
+		if err != nil {
+			return nil, err
+		}
+		a := parseArchive(data)
+		a.set(f.Section, content)
+		content = a.format()
+	}
+	return c.compress(content)
+}
+
+// name identifies the file, or section, in update records and errors.
+func (f *File) name() string {
+	if f.Section == "" {
+		return f.diskPath()
+	}
+	return f.diskPath() + "#" + f.Section
+}
+
+// diskPath returns the path of the file on disk, which is written in update
+// mode.
+func (f *File) diskPath() string {
+	if f.Root == "" {
+		return f.Path
+	}
+	return filepath.Join(f.Root, filepath.FromSlash(f.Path))
+}
+
+// golden returns the expected value to read for a comparison. In update mode,
+// the content of a golden File is read up front, and a missing file, or
+// section, is replaced by empty content, so that it is created.
+func golden(expected interface{}) interface{} {
+	f, ok := expected.(*File)
+	if !ok || !Updating(f.diskPath()) {
+		return expected
+	}
+	content, err := f.Bytes()
+	switch {
+	case err == nil:
+		return content
+	case errors.Is(err, fs.ErrNotExist):
+		return []byte{}
+	}
+	// Other errors are reported by the comparison.
+	return expected
+}
@@ -1,7 +1,9 @@
+//go:build !update
 // +build !update
 
 package diff
 
 // UpdateMode indicates whether a detected diff should cause a File to be
-// overwritten. To enable UpdateMode, run `go test -tag=update`.
+// overwritten. To enable UpdateMode, run `go test -tags=update`. See Updating
+// for ways to enable update mode at runtime, without recompiling.
 const UpdateMode = false
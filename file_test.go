@@ -1,10 +1,17 @@
 package diff
 
 import (
+	"embed"
+	"errors"
 	"io/ioutil"
+	"os"
 	"testing"
+	"testing/fstest"
 )
 
+//go:embed testdata/test.txt
+var embedded embed.FS
+
 func TestFile(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -37,3 +44,230 @@ func TestFile(t *testing.T) {
 		}
 	}
 }
+
+func TestFileReuse(t *testing.T) {
+	f := &File{Path: "testdata/test.txt"}
+	for i := 0; i < 2; i++ {
+		content, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "Test Content\n" {
+			t.Errorf("Read %d: unexpected content: %q", i, content)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if d := Text(f, "Test Content"); d != nil {
+			t.Errorf("Comparison %d: %s", i, d)
+		}
+	}
+	// A partial read does not affect comparisons.
+	if _, err := f.Read(make([]byte, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if d := Text(f, "Test Content"); d != nil {
+		t.Errorf("Comparison after partial read: %s", d)
+	}
+}
+
+func TestFileBytes(t *testing.T) {
+	content, err := (&File{Path: "testdata/test.txt"}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Test Content\n" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+	if _, err := (&File{Path: "testdata/not_found"}).Bytes(); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got: %v", err)
+	}
+}
+
+func TestFileExists(t *testing.T) {
+	if !(&File{Path: "testdata/test.txt"}).Exists() {
+		t.Error("Expected testdata/test.txt to exist")
+	}
+	if (&File{Path: "testdata/not_found"}).Exists() {
+		t.Error("Expected testdata/not_found not to exist")
+	}
+	if !Section("testdata/sections.txtar", "text").Exists() {
+		t.Error("Expected the text section to exist")
+	}
+	t.Run("not a read", func(t *testing.T) {
+		defer withRegistry()()
+		(&File{Path: "testdata/test.txt"}).Exists()
+		Section("testdata/sections.txtar", "text").Exists()
+		if goldens.wasRead("testdata/test.txt") || goldens.wasRead("testdata/sections.txtar") {
+			t.Error("Expected Exists not to record a read")
+		}
+	})
+}
+
+func TestMissingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/missing.golden"
+
+	if !Updating(path) {
+		if d := Text(&File{Path: path}, "foo"); !errors.Is(d, os.ErrNotExist) {
+			t.Errorf("Expected a not-exist error, got: %v", d)
+		}
+	}
+	defer setenv(t, UpdateEnv, "true")()
+	if d := Text("foo", &File{Path: path}); !errors.Is(d, os.ErrNotExist) {
+		t.Errorf("Expected a not-exist error for a missing actual file, got: %v", d)
+	}
+	if d := Text(&File{Path: path}, "foo"); d != nil {
+		t.Fatalf("Unexpected result in update mode: %s", d)
+	}
+	if d := Text("foo", &File{Path: path}); d != nil {
+		t.Errorf("Unexpected golden file content:\n%s", d)
+	}
+}
+
+func TestFileFS(t *testing.T) {
+	t.Run("embed", func(t *testing.T) {
+		if d := Text(&File{FS: embedded, Path: "testdata/test.txt"}, "Test Content"); d != nil {
+			t.Error(d)
+		}
+	})
+	fsys := fstest.MapFS{
+		"goldens/foo.golden": &fstest.MapFile{Data: []byte("foo\n")},
+	}
+	t.Run("map", func(t *testing.T) {
+		f := &File{FS: fsys, Path: "goldens/foo.golden"}
+		if !f.Exists() {
+			t.Error("Expected file to exist")
+		}
+		if d := Text(f, "foo"); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		f := &File{FS: fsys, Path: "goldens/bar.golden", Root: "testdata/not_found"}
+		if f.Exists() {
+			t.Error("Expected file not to exist")
+		}
+		if Updating(f.diskPath()) {
+			t.Skip("update mode enabled")
+		}
+		if d := Text(f, "bar"); !errors.Is(d, os.ErrNotExist) {
+			t.Errorf("Expected a not-exist error, got: %v", d)
+		}
+	})
+	t.Run("update", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "diff-fs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		defer setenv(t, UpdateEnv, "true")()
+		f := &File{FS: fsys, Path: "goldens/foo.golden", Root: dir, CreateDirs: true}
+		if d := Text(f, "updated"); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		if d := Text("updated", &File{Path: dir + "/goldens/foo.golden"}); d != nil {
+			t.Errorf("Unexpected golden file content:\n%s", d)
+		}
+		// The file system itself is unchanged.
+		if d := Text("foo", &File{FS: fsys, Path: "goldens/foo.golden"}); d != nil {
+			t.Errorf("Unexpected file system content:\n%s", d)
+		}
+	})
+}
+
+func TestSection(t *testing.T) {
+	t.Run("read", func(t *testing.T) {
+		if d := Text(Section("testdata/sections.txtar", "text"), "Test Content"); d != nil {
+			t.Error(d)
+		}
+		if d := JSON(Section("testdata/sections.txtar", "json"), []byte(`{"foo":"bar"}`)); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		f := Section("testdata/sections.txtar", "missing")
+		if f.Exists() {
+			t.Error("Expected section not to exist")
+		}
+		_, err := f.Bytes()
+		var sectionErr *SectionError
+		if !errors.As(err, &sectionErr) || !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected a *SectionError, got: %v", err)
+		}
+	})
+	t.Run("update", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "diff-section")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path := dir + "/foo.txtar"
+		if e := ioutil.WriteFile(path, []byte("comment\n-- a --\na\n-- b --\nb\n"), 0666); e != nil {
+			t.Fatal(e)
+		}
+		defer setenv(t, UpdateEnv, "true")()
+		if d := Text(Section(path, "a"), "new a"); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		if d := Text(Section(path, "c"), "new c"); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		// Updates to different sections of one archive do not conflict.
+		if d := Text(Section(path, "b"), "b"); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		expected := "comment\n-- a --\nnew a\n-- b --\nb\n-- c --\nnew c\n"
+		if d := Text(expected, &File{Path: path}); d != nil {
+			t.Errorf("Unexpected archive content:\n%s", d)
+		}
+	})
+}
+
+func TestCompressedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-compressed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setenv(t, UpdateEnv, "true")()
+
+	tests := []struct {
+		name   string
+		golden *File
+		c      Compression
+	}{
+		{name: "gzip", golden: &File{Path: dir + "/foo.json.gz"}, c: Gzip},
+		{name: "zstd", golden: &File{Path: dir + "/foo.json.zst"}, c: Zstd},
+		{name: "explicit", golden: &File{Path: dir + "/foo.golden", Compression: Gzip}, c: Gzip},
+		{name: "section", golden: &File{Path: dir + "/foo.txtar.gz", Section: "json"}, c: Gzip},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if d := AsJSON(test.golden, map[string]string{"foo": "bar"}); d != nil {
+				t.Fatalf("Unexpected result in update mode: %s", d)
+			}
+			if d := AsJSON(test.golden, map[string]string{"foo": "bar"}); d != nil {
+				t.Errorf("Unexpected result after update: %s", d)
+			}
+			raw, err := ioutil.ReadFile(test.golden.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := test.c.decompress(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := "{\n    \"foo\": \"bar\"\n}"
+			if test.golden.Section != "" {
+				expected = "-- json --\n" + expected + "\n"
+			}
+			if d := Text(expected, content); d != nil {
+				t.Errorf("Unexpected file content:\n%s", d)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
+//go:build update
 // +build update
 
 package diff
 
+// UpdateMode indicates whether a detected diff should cause a File to be
+// overwritten. It is true, because the package was built with
+// `go test -tags=update`. See Updating for ways to enable update mode at
+// runtime.
 const UpdateMode = true
@@ -0,0 +1,58 @@
+package diff
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// matchGlob reports whether name matches pattern. Patterns use the syntax of
+// path.Match, with forward slashes as separators, and additionally a "**"
+// path segment matches zero or more segments.
+func matchGlob(pattern, name string) bool {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	name = filepath.ToSlash(filepath.Clean(name))
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// globRoot returns the longest leading directory of pattern which contains no
+// wildcards, from which matching files may be searched.
+func globRoot(pattern string) string {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	var root []string
+	for _, segment := range segments[:len(segments)-1] {
+		if segment == "**" || strings.ContainsAny(segment, `*?[\`) {
+			break
+		}
+		root = append(root, segment)
+	}
+	switch joined := strings.Join(root, "/"); {
+	case joined != "":
+		return filepath.FromSlash(joined)
+	case len(root) > 0:
+		// The pattern is rooted, such as "/*.golden".
+		return string(filepath.Separator)
+	}
+	return "."
+}
@@ -0,0 +1,48 @@
+package diff
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		expected      bool
+	}{
+		{"testdata/*.golden", "testdata/foo.golden", true},
+		{"testdata/*.golden", "./testdata/foo.golden", true},
+		{"testdata/*.golden", "testdata/sub/foo.golden", false},
+		{"testdata/**/*.golden", "testdata/foo.golden", true},
+		{"testdata/**/*.golden", "testdata/a/b/foo.golden", true},
+		{"testdata/**", "testdata/a/b/foo.golden", true},
+		{"**/*.json", "foo.json", true},
+		{"**/*.json", "a/foo.txt", false},
+		{"testdata/foo.txt", "testdata/foo.txt", true},
+		{"testdata/foo.txt", "testdata/foo.txt/bar", false},
+		{"testdata/[", "testdata/[", false},
+	}
+	for _, test := range tests {
+		if result := matchGlob(test.pattern, test.name); result != test.expected {
+			t.Errorf("matchGlob(%q, %q) = %t", test.pattern, test.name, result)
+		}
+	}
+}
+
+func TestGlobRoot(t *testing.T) {
+	tests := []struct {
+		pattern, expected string
+	}{
+		{"testdata/*.golden", "testdata"},
+		{"testdata/**/*.golden", "testdata"},
+		{"testdata/a/b/*.golden", "testdata/a/b"},
+		{"testdata/a*/b/*.golden", "testdata"},
+		{"./testdata/foo.golden", "testdata"},
+		{"*.golden", "."},
+		{"**/*.golden", "."},
+		{"/tmp/**/*.golden", "/tmp"},
+		{"/*.golden", "/"},
+	}
+	for _, test := range tests {
+		if result := globRoot(test.pattern); result != test.expected {
+			t.Errorf("globRoot(%q) = %q, expected %q", test.pattern, result, test.expected)
+		}
+	}
+}
@@ -3,10 +3,10 @@ package diff
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"reflect"
 	"strings"
 )
 
@@ -18,18 +18,26 @@ import (
 // - string
 // - []byte
 func HTTPRequest(expected, actual interface{}) *Result {
-	expDump, expErr := dumpRequest(expected)
+	return HTTPRequestWith(expected, actual)
+}
+
+// HTTPRequestWith is like HTTPRequest, but accepts options to configure the
+// comparison.
+func HTTPRequestWith(expected, actual interface{}, opts ...Option) *Result {
+	o := newOptions(opts)
+	expDump, expErr := dumpRequest(golden(expected))
 	actDump, err := dumpRequest(actual)
 	if err != nil {
-		return &Result{err: fmt.Sprintf("Failed to dump actual request: %s", err)}
+		return &Result{err: &DumpError{Side: SideActual, Kind: "request", Err: err}}
 	}
 	var d *Result
+	act := string(actDump)
 	if expErr != nil {
-		d = &Result{err: fmt.Sprintf("Failed to dump expected request: %s", expErr)}
+		d = &Result{err: &DumpError{Side: SideExpected, Kind: "request", Err: expErr}}
 	} else {
-		d = Text(string(expDump), string(actDump))
+		d, act = compare(string(expDump), string(actDump), o)
 	}
-	return update(UpdateMode, expected, string(actDump), d)
+	return update(updating(expected), expected, act, d, o)
 }
 
 func toRequest(i interface{}) (*http.Request, error) {
@@ -37,6 +45,12 @@ func toRequest(i interface{}) (*http.Request, error) {
 	switch t := i.(type) {
 	case *http.Request:
 		return t, nil
+	case *File:
+		content, err := t.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(content)
 	case io.Reader:
 		r = t
 	case string:
@@ -44,7 +58,7 @@ func toRequest(i interface{}) (*http.Request, error) {
 	case []byte:
 		r = bytes.NewReader(t)
 	default:
-		return nil, fmt.Errorf("Unable to convert %T to *http.Request", i)
+		return nil, &TypeError{Type: reflect.TypeOf(i), Want: "*http.Request, io.Reader, string, or []byte"}
 	}
 	return http.ReadRequest(bufio.NewReader(r))
 }
@@ -68,18 +82,26 @@ func dumpRequest(i interface{}) ([]byte, error) {
 // - string
 // - []byte
 func HTTPResponse(expected, actual interface{}) *Result {
-	expDump, expErr := dumpResponse(expected)
+	return HTTPResponseWith(expected, actual)
+}
+
+// HTTPResponseWith is like HTTPResponse, but accepts options to configure the
+// comparison.
+func HTTPResponseWith(expected, actual interface{}, opts ...Option) *Result {
+	o := newOptions(opts)
+	expDump, expErr := dumpResponse(golden(expected))
 	actDump, err := dumpResponse(actual)
 	if err != nil {
-		return &Result{err: fmt.Sprintf("Failed to dump actual response: %s", err)}
+		return &Result{err: &DumpError{Side: SideActual, Kind: "response", Err: err}}
 	}
 	var d *Result
+	act := string(actDump)
 	if expErr != nil {
-		d = &Result{err: fmt.Sprintf("Failed to dump expected response: %s", expErr)}
+		d = &Result{err: &DumpError{Side: SideExpected, Kind: "response", Err: expErr}}
 	} else {
-		d = Text(string(expDump), string(actDump))
+		d, act = compare(string(expDump), string(actDump), o)
 	}
-	return update(UpdateMode, expected, string(actDump), d)
+	return update(updating(expected), expected, act, d, o)
 }
 
 func toResponse(i interface{}) (*http.Response, error) {
@@ -87,6 +109,12 @@ func toResponse(i interface{}) (*http.Response, error) {
 	switch t := i.(type) {
 	case *http.Response:
 		return t, nil
+	case *File:
+		content, err := t.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(content)
 	case io.Reader:
 		r = t
 	case string:
@@ -94,7 +122,7 @@ func toResponse(i interface{}) (*http.Response, error) {
 	case []byte:
 		r = bytes.NewReader(t)
 	default:
-		return nil, fmt.Errorf("Unable to convert %T to *http.Response", i)
+		return nil, &TypeError{Type: reflect.TypeOf(i), Want: "*http.Response, io.Reader, string, or []byte"}
 	}
 	return http.ReadResponse(bufio.NewReader(r), nil)
 }
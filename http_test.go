@@ -93,7 +93,7 @@ Accept: */*
 			name:     "unknown input type",
 			expected: 123,
 			actual:   nil,
-			result:   "Failed to dump expected request: Unable to convert int to *http.Request",
+			result:   "Failed to dump expected request: input must be of type *http.Request, io.Reader, string, or []byte, not int",
 		},
 	}
 	for _, test := range tests {
@@ -214,7 +214,7 @@ Date: Tue, 22 Jan 2019 18:44:09 GMT
 		{
 			name:     "unknown input type",
 			expected: int(123),
-			result:   "Failed to dump expected response: Unable to convert int to *http.Response",
+			result:   "Failed to dump expected response: input must be of type *http.Response, io.Reader, string, or []byte, not int",
 		},
 	}
 	for _, test := range tests {
@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/flimzy/diff/engine"
+)
+
+// LineKind identifies the role of a Line within a Hunk.
+type LineKind int
+
+const (
+	// Context is a line found unchanged in both inputs.
+	Context LineKind = iota
+	// Delete is a line found only in the expected input.
+	Delete
+	// Insert is a line found only in the actual input.
+	Insert
+)
+
+func (k LineKind) String() string {
+	switch k {
+	case Context:
+		return "context"
+	case Delete:
+		return "delete"
+	case Insert:
+		return "insert"
+	}
+	return fmt.Sprintf("LineKind(%d)", int(k))
+}
+
+// prefix returns the unified diff prefix for the line kind.
+func (k LineKind) prefix() string {
+	switch k {
+	case Delete:
+		return "-"
+	case Insert:
+		return "+"
+	}
+	return " "
+}
+
+// Line is a single line of a Hunk. Text is the line as read from the input,
+// including the trailing newline, if any.
+type Line struct {
+	Kind LineKind
+	Text string
+}
+
+// Hunk is a contiguous region of differences, along with surrounding context.
+// OldStart and NewStart are the 1-based line numbers of the first line of the
+// hunk in the expected and actual inputs, respectively. OldLines and NewLines
+// are the number of lines the hunk spans in each input.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Header returns the unified diff hunk header, such as "@@ -1,3 +1,4 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", formatRange(h.OldStart, h.OldLines), formatRange(h.NewStart, h.NewLines))
+}
+
+// formatRange formats a hunk range per the unified diff spec.
+func formatRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	if length == 0 {
+		// Empty ranges begin at the line just before the range.
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+// Stats summarizes the differences in a Result.
+type Stats struct {
+	Hunks      int
+	Insertions int
+	Deletions  int
+}
+
+// buildHunks converts grouped opcodes, as returned by engine.Group, into hunks.
+func buildHunks(a, b []string, groups [][]engine.OpCode) []Hunk {
+	hunks := make([]Hunk, 0, len(groups))
+	for _, g := range groups {
+		first, last := g[0], g[len(g)-1]
+		h := Hunk{
+			OldStart: first.I1 + 1,
+			OldLines: last.I2 - first.I1,
+			NewStart: first.J1 + 1,
+			NewLines: last.J2 - first.J1,
+		}
+		for _, c := range g {
+			if c.Tag == 'e' {
+				for _, line := range a[c.I1:c.I2] {
+					h.Lines = append(h.Lines, Line{Kind: Context, Text: line})
+				}
+				continue
+			}
+			if c.Tag == 'r' || c.Tag == 'd' {
+				for _, line := range a[c.I1:c.I2] {
+					h.Lines = append(h.Lines, Line{Kind: Delete, Text: line})
+				}
+			}
+			if c.Tag == 'r' || c.Tag == 'i' {
+				for _, line := range b[c.J1:c.J2] {
+					h.Lines = append(h.Lines, Line{Kind: Insert, Text: line})
+				}
+			}
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
@@ -0,0 +1,48 @@
+package diff
+
+import "testing"
+
+func TestHunkHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		hunk     Hunk
+		expected string
+	}{
+		{
+			name:     "single lines",
+			hunk:     Hunk{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1},
+			expected: "@@ -1 +1 @@",
+		},
+		{
+			name:     "multiple lines",
+			hunk:     Hunk{OldStart: 3, OldLines: 4, NewStart: 5, NewLines: 2},
+			expected: "@@ -3,4 +5,2 @@",
+		},
+		{
+			name:     "empty old range",
+			hunk:     Hunk{OldStart: 1, OldLines: 0, NewStart: 1, NewLines: 3},
+			expected: "@@ -0,0 +1,3 @@",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.hunk.Header(); result != test.expected {
+				t.Errorf("Unexpected result: %s", result)
+			}
+		})
+	}
+}
+
+func TestLineKindString(t *testing.T) {
+	tests := map[LineKind]string{
+		Context:     "context",
+		Delete:      "delete",
+		Insert:      "insert",
+		LineKind(9): "LineKind(9)",
+	}
+	for kind, expected := range tests {
+		if result := kind.String(); result != expected {
+			t.Errorf("Unexpected result for %d: %s", int(kind), result)
+		}
+	}
+}
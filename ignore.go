@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// IgnorePointer causes AsJSON and JSON to ignore the values at the given RFC
+// 6901 JSON Pointers, such as "/meta/etag", in both the expected and actual
+// documents. Ignored values are removed before the documents are compared and
+// rendered. In update mode (see Updating), they are still written to the
+// golden file, so that it may be shared with comparisons which do not ignore
+// them.
+func IgnorePointer(pointers ...string) Option {
+	return func(o *options) {
+		for _, p := range pointers {
+			o.ignores = append(o.ignores, ignoreRule{tokens: parsePointer(p)})
+		}
+	}
+}
+
+// IgnorePath is like IgnorePointer, but each reference token of the given
+// pointers may be "*", to match any object member or array element, as in
+// "/items/*/id".
+func IgnorePath(patterns ...string) Option {
+	return func(o *options) {
+		for _, p := range patterns {
+			o.ignores = append(o.ignores, ignoreRule{tokens: parsePointer(p), wildcard: true})
+		}
+	}
+}
+
+// IgnoreKey is like IgnorePointer, but ignores object members with the given
+// names, such as "created_at", at any depth.
+func IgnoreKey(keys ...string) Option {
+	return func(o *options) {
+		for _, k := range keys {
+			o.ignores = append(o.ignores, ignoreRule{key: k, anyDepth: true})
+		}
+	}
+}
+
+// ignoreRule identifies JSON values to ignore.
+type ignoreRule struct {
+	// tokens are the reference tokens of the pointer to ignore.
+	tokens []string
+	// wildcard enables "*" tokens.
+	wildcard bool
+	// anyDepth means that object members named key are ignored, wherever
+	// they occur.
+	anyDepth bool
+	key      string
+}
+
+func (r ignoreRule) match(path []string, member bool) bool {
+	if r.anyDepth {
+		return member && len(path) > 0 && path[len(path)-1] == r.key
+	}
+	if len(path) != len(r.tokens) {
+		return false
+	}
+	for i, token := range r.tokens {
+		if path[i] != token && !(r.wildcard && token == "*") {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePointer returns the unescaped reference tokens of the JSON Pointer p.
+func parsePointer(p string) []string {
+	if p == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens
+}
+
+// ignored reports whether the value at path is to be ignored. member is true
+// if the value is an object member, rather than an array element.
+func (o *options) ignored(path []string, member bool) bool {
+	for _, r := range o.ignores {
+		if r.match(path, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterJSON removes the ignored values from the indented JSON document buf,
+// as returned by marshal. If buf cannot be decoded, it is returned unchanged.
+func (o *options) filterJSON(buf []byte) []byte {
+	if len(o.ignores) == 0 {
+		return buf
+	}
+	var doc interface{}
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return buf
+	}
+	filtered, err := json.MarshalIndent(o.filter(nil, doc), "", "    ")
+	if err != nil {
+		return buf
+	}
+	return filtered
+}
+
+func (o *options) filter(path []string, v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			p := append(path[:len(path):len(path)], k)
+			if !o.ignored(p, true) {
+				result[k] = o.filter(p, child)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(t))
+		for i, child := range t {
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if !o.ignored(p, false) {
+				result = append(result, o.filter(p, child))
+			}
+		}
+		return result
+	}
+	return v
+}
@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	tests := map[string][]string{
+		"":          nil,
+		"/":         {""},
+		"/foo/0":    {"foo", "0"},
+		"/a~1b/c~0": {"a/b", "c~"},
+		"/~01":      {"~1"},
+	}
+	for pointer, expected := range tests {
+		if d := Interface(expected, parsePointer(pointer)); d != nil {
+			t.Errorf("%q: %s", pointer, d)
+		}
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	expected := []byte(`{
+		"id": 1,
+		"created_at": "2019-01-22",
+		"meta": {"etag": "abc", "version": 1},
+		"items": [
+			{"id": 10, "name": "foo", "created_at": "x"},
+			{"id": 11, "name": "bar"}
+		]
+	}`)
+	actual := []byte(`{
+		"id": 2,
+		"created_at": "2020-02-23",
+		"meta": {"etag": "def", "version": 1},
+		"items": [
+			{"id": 20, "name": "foo", "created_at": "y"},
+			{"id": 21, "name": "bar"}
+		]
+	}`)
+	t.Run("no options", func(t *testing.T) {
+		if d := JSON(expected, actual); d == nil {
+			t.Error("Expected a difference")
+		}
+	})
+	t.Run("all ignored", func(t *testing.T) {
+		opts := []Option{IgnorePointer("/id", "/meta/etag"), IgnorePath("/items/*/id"), IgnoreKey("created_at")}
+		if d := JSONWith(expected, actual, opts...); d != nil {
+			t.Error(d)
+		}
+		if d := AsJSONWith(expected, actual, opts...); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("partially ignored", func(t *testing.T) {
+		d := JSONWith(expected, actual, IgnoreKey("created_at", "etag"), IgnorePointer("/items/0/id"), JSONPointers())
+		want := "/id: 1 → 2\n/items/1/id: 11 → 21\n"
+		if d.String() != want {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("key is not an index", func(t *testing.T) {
+		if d := AsJSONWith([]interface{}{1, 2}, []interface{}{1, 3}, IgnoreKey("1")); d == nil {
+			t.Error("Expected a difference")
+		}
+	})
+	t.Run("array elements", func(t *testing.T) {
+		if d := AsJSONWith([]interface{}{1, 2, 3}, []interface{}{1, 5, 3}, IgnorePointer("/1")); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("rendering", func(t *testing.T) {
+		d := AsJSONWith(map[string]int{"a": 1, "b": 2}, map[string]int{"a": 3, "b": 4}, IgnorePointer("/b"))
+		want := "--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n {\n-    \"a\": 1\n+    \"a\": 3\n }\n"
+		if d.String() != want {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("update", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "diff-ignore")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		defer setenv(t, UpdateEnv, "true")()
+		path := dir + "/foo.json"
+		if d := JSONWith(&File{Path: path}, []byte(`{"a":1,"id":5}`), IgnoreKey("id")); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		if d := Text("{\n    \"a\": 1,\n    \"id\": 5\n}", &File{Path: path}); d != nil {
+			t.Errorf("Unexpected golden file content:\n%s", d)
+		}
+		if d := JSONWith(&File{Path: path}, []byte(`{"a":1,"id":6}`), IgnoreKey("id")); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		if d := Text("{\n    \"a\": 1,\n    \"id\": 5\n}", &File{Path: path}); d != nil {
+			t.Errorf("Expected an ignored change to leave the golden file intact:\n%s", d)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind identifies the kind of a Change.
+type ChangeKind int
+
+const (
+	// Replaced means that the value differs between the documents.
+	Replaced ChangeKind = iota
+	// Added means that the value exists only in the actual document.
+	Added
+	// Removed means that the value exists only in the expected document.
+	Removed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Replaced:
+		return "replaced"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a single difference between two JSON documents, as found by
+// AsJSON and JSON.
+type Change struct {
+	// Path is the RFC 6901 JSON Pointer to the value, such as
+	// "/items/3/price". The empty string refers to the whole document. The
+	// indexes of removed array elements refer to the expected document, and
+	// all others to the actual document. Arrays reordered by UnorderedArrays
+	// or SortArray are indexed in their reordered, rather than their
+	// original, order.
+	Path string
+	Kind ChangeKind
+	// Old is the expected value, and New the actual value, as decoded by
+	// encoding/json. Old is nil for Added values, and New for Removed ones.
+	Old, New interface{}
+}
+
+// String returns a description of the change, such as
+// "/items/3/price: 10 → 12".
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "(root)"
+	}
+	switch c.Kind {
+	case Added:
+		return path + ": added " + jsonValue(c.New)
+	case Removed:
+		return path + ": removed " + jsonValue(c.Old)
+	}
+	return path + ": " + jsonValue(c.Old) + " → " + jsonValue(c.New)
+}
+
+// JSONPointers causes the differences found by AsJSON and JSON to be rendered
+// as a list of changes, one per line, each identified by its JSON Pointer, as
+// in:
+//
+//	/items/3/price: 10 → 12
+//	/items/4: added {"id":5}
+//
+// rather than as a unified diff. The output of other comparisons is not
+// affected.
+func JSONPointers() Option {
+	return func(o *options) {
+		o.jsonPointers = true
+	}
+}
+
+// Changes returns the structural differences found between two JSON
+// documents by AsJSON or JSON, ordered by path. It returns nil for other
+// comparisons, or if r is nil or represents an error.
+func (r *Result) Changes() []Change {
+	if r == nil {
+		return nil
+	}
+	return r.changes
+}
+
+// jsonChanges returns the differences between two decoded JSON documents.
+func jsonChanges(expected, actual interface{}) []Change {
+	var changes []Change
+	walkJSON("", expected, actual, &changes)
+	return changes
+}
+
+func walkJSON(path string, expected, actual interface{}, changes *[]Change) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ev, inE := e[k]
+			av, inA := a[k]
+			p := path + "/" + escapePointer(k)
+			switch {
+			case !inA:
+				*changes = append(*changes, Change{Path: p, Kind: Removed, Old: ev})
+			case !inE:
+				*changes = append(*changes, Change{Path: p, Kind: Added, New: av})
+			default:
+				walkJSON(p, ev, av, changes)
+			}
+		}
+		return
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(e) && i < len(a); i++ {
+			walkJSON(path+"/"+strconv.Itoa(i), e[i], a[i], changes)
+		}
+		for i := len(e); i < len(a); i++ {
+			*changes = append(*changes, Change{Path: path + "/" + strconv.Itoa(i), Kind: Added, New: a[i]})
+		}
+		for i := len(a); i < len(e); i++ {
+			*changes = append(*changes, Change{Path: path + "/" + strconv.Itoa(i), Kind: Removed, Old: e[i]})
+		}
+		return
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		*changes = append(*changes, Change{Path: path, Kind: Replaced, Old: expected, New: actual})
+	}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointer escapes a reference token of a JSON Pointer.
+func escapePointer(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+// jsonValue returns v as compact JSON.
+func jsonValue(v interface{}) string {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// renderChanges renders changes one per line, as selected by JSONPointers.
+func renderChanges(o *options, changes []Change) string {
+	p := painter(o.color.enabled())
+	buf := &strings.Builder{}
+	for _, c := range changes {
+		switch c.Kind {
+		case Added:
+			buf.WriteString(p.paint(ansiGreen, c.String()))
+		case Removed:
+			buf.WriteString(p.paint(ansiRed, c.String()))
+		default:
+			path := c.Path
+			if path == "" {
+				path = "(root)"
+			}
+			buf.WriteString(path + ": " + p.paint(ansiRed, jsonValue(c.Old)) + " → " + p.paint(ansiGreen, jsonValue(c.New)))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
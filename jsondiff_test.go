@@ -0,0 +1,116 @@
+package diff
+
+import "testing"
+
+func TestJSONChanges(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		changes          []string
+	}{
+		{
+			name:     "equal",
+			expected: `{"a":[1,2,{"b":null}]}`,
+			actual:   `{"a":[1,2,{"b":null}]}`,
+		},
+		{
+			name:     "root",
+			expected: `1`,
+			actual:   `"1"`,
+			changes:  []string{`(root): 1 → "1"`},
+		},
+		{
+			name:     "nested",
+			expected: `{"items":[{"price":10},{"price":5}],"total":15}`,
+			actual:   `{"items":[{"price":12},{"price":5}],"total":17}`,
+			changes: []string{
+				"/items/0/price: 10 → 12",
+				"/total: 15 → 17",
+			},
+		},
+		{
+			name:     "members",
+			expected: `{"a":1,"b":{"c":true}}`,
+			actual:   `{"a":1,"d":[1,"<x>"]}`,
+			changes: []string{
+				`/b: removed {"c":true}`,
+				`/d: added [1,"<x>"]`,
+			},
+		},
+		{
+			name:     "elements",
+			expected: `[[1,2,3],[1]]`,
+			actual:   `[[1],[1,2]]`,
+			changes: []string{
+				"/0/1: removed 2",
+				"/0/2: removed 3",
+				"/1/1: added 2",
+			},
+		},
+		{
+			name:     "type change",
+			expected: `{"a":{"b":1}}`,
+			actual:   `{"a":[1]}`,
+			changes:  []string{`/a: {"b":1} → [1]`},
+		},
+		{
+			name:     "escaped",
+			expected: `{"a/b":{"c~d":1}}`,
+			actual:   `{"a/b":{"c~d":2}}`,
+			changes:  []string{"/a~1b/c~0d: 1 → 2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := JSON([]byte(test.expected), []byte(test.actual))
+			if d.Err() != nil {
+				t.Fatal(d.Err())
+			}
+			var changes []string
+			for _, c := range d.Changes() {
+				changes = append(changes, c.String())
+			}
+			if d := Interface(test.changes, changes); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestChange(t *testing.T) {
+	c := Change{Path: "/a", Kind: Added, New: 1.0}
+	if c.Kind.String() != "added" || c.Old != nil {
+		t.Errorf("Unexpected change: %+v", c)
+	}
+	if s := ChangeKind(5).String(); s != "ChangeKind(5)" {
+		t.Errorf("Unexpected string: %s", s)
+	}
+}
+
+func TestJSONPointers(t *testing.T) {
+	expected := map[string]interface{}{"items": []interface{}{map[string]interface{}{"price": 10}}, "name": "foo"}
+	actual := map[string]interface{}{"items": []interface{}{map[string]interface{}{"price": 12}, 3}, "name": "foo"}
+	d := AsJSONWith(expected, actual, JSONPointers())
+	want := "/items/0/price: 10 → 12\n/items/1: added 3\n"
+	if d.String() != want {
+		t.Errorf("Unexpected output:\n%s", d)
+	}
+	colored := d.Render(Color(ColorAlways))
+	wantColored := "/items/0/price: " + ansiRed + "10" + ansiReset + " → " + ansiGreen + "12" + ansiReset + "\n" +
+		ansiGreen + "/items/1: added 3" + ansiReset + "\n"
+	if colored != wantColored {
+		t.Errorf("Unexpected colored output: %q", colored)
+	}
+	// The unified diff remains the default rendering.
+	unified := AsJSON(expected, actual)
+	if unified.String() == want || len(unified.Hunks()) == 0 {
+		t.Errorf("Unexpected default output:\n%s", unified)
+	}
+	if len(unified.Changes()) != 2 {
+		t.Errorf("Expected 2 changes, got %d", len(unified.Changes()))
+	}
+	// Other comparisons are unaffected.
+	if d := TextWith("foo", "bar", JSONPointers()); d.String() != Text("foo", "bar").String() {
+		t.Errorf("Unexpected text output:\n%s", d)
+	}
+}
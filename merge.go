@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// MergePatch returns an RFC 7386 JSON Merge Patch which transforms the
+// expected JSON document into the actual one. expected and actual are
+// interpreted as by AsJSON.
+//
+// As a merge patch uses null to remove object members, a member whose actual
+// value is null cannot be expressed, and is removed by the patch instead.
+// Arrays are replaced as a whole.
+func MergePatch(expected, actual interface{}) (json.RawMessage, error) {
+	e, err := decodeJSON(SideExpected, expected)
+	if err != nil {
+		return nil, err
+	}
+	a, err := decodeJSON(SideActual, actual)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(e, a))
+}
+
+func mergePatch(expected, actual interface{}) interface{} {
+	e, eOK := expected.(map[string]interface{})
+	a, aOK := actual.(map[string]interface{})
+	if !eOK || !aOK {
+		return actual
+	}
+	patch := make(map[string]interface{})
+	for k := range e {
+		if _, ok := a[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, av := range a {
+		ev, ok := e[k]
+		switch {
+		case !ok:
+			patch[k] = mergePatch(nil, av)
+		case !reflect.DeepEqual(ev, av):
+			patch[k] = mergePatch(ev, av)
+		}
+	}
+	return patch
+}
+
+// ApplyMergePatch applies the RFC 7386 JSON Merge Patch patch to the JSON
+// document doc, and returns the result. doc and patch are interpreted as by
+// AsJSON.
+func ApplyMergePatch(doc, patch interface{}) (json.RawMessage, error) {
+	d, err := decodeJSON(SideExpected, doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeJSON(SideExpected, patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(applyMergePatch(d, p))
+}
+
+func applyMergePatch(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]interface{})
+	if !ok {
+		d = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = applyMergePatch(d[k], v)
+	}
+	return d
+}
+
+// Merged is an expected value for AsJSON and AsJSONWith, which is the JSON
+// document Base with the RFC 7386 JSON Merge Patch Patch applied. This allows
+// an expected document to be expressed as a shared base golden file, plus a
+// small patch. Base and Patch may be of any type accepted by AsJSON,
+// including *File.
+//
+// In update mode (see Updating), if Patch is a *File, it is overwritten with
+// the merge patch which transforms Base into the actual value, when a
+// difference is detected. Base is never updated.
+type Merged struct {
+	Base  interface{}
+	Patch interface{}
+}
+
+// asJSON compares the merged document to actual.
+func (m *Merged) asJSON(actual interface{}, o *options) *Result {
+	// Base and actual may be readers, so each is decoded once, and the
+	// decoded values are used both for the comparison and for the patch.
+	base, err := decodeJSON(SideExpected, m.Base)
+	if err != nil {
+		return &Result{err: err}
+	}
+	act, err := decodeJSON(SideActual, actual)
+	if err != nil {
+		return &Result{err: err}
+	}
+	var d *Result
+	if doc, err := ApplyMergePatch(base, golden(m.Patch)); err != nil {
+		d = &Result{err: err}
+	} else {
+		d = asJSON(doc, act, o)
+	}
+	if !updating(m.Patch) {
+		return d
+	}
+	patch, err := MergePatch(base, act)
+	if err != nil {
+		return &Result{err: err}
+	}
+	patchJSON, err := marshal(patch)
+	if err != nil {
+		return &Result{err: &MarshalError{Side: SideActual, Op: "marshal", Err: err}}
+	}
+	return update(true, m.Patch, string(patchJSON), d, o)
+}
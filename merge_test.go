@@ -0,0 +1,183 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// Test cases from RFC 7386, Appendix A.
+	tests := []struct {
+		doc, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.doc+" + "+test.patch, func(t *testing.T) {
+			result, err := ApplyMergePatch([]byte(test.doc), []byte(test.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := JSON([]byte(test.expected), result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		patch            string
+	}{
+		{
+			name:     "equal",
+			expected: `{"a":1}`,
+			actual:   `{"a":1}`,
+			patch:    `{}`,
+		},
+		{
+			name:     "members",
+			expected: `{"a":1,"b":{"c":2,"d":3},"e":[1]}`,
+			actual:   `{"a":1,"b":{"c":4,"d":3},"e":[1,2],"f":true}`,
+			patch:    `{"b":{"c":4},"e":[1,2],"f":true}`,
+		},
+		{
+			name:     "removed",
+			expected: `{"a":1,"b":{"c":2}}`,
+			actual:   `{"b":{}}`,
+			patch:    `{"a":null,"b":{"c":null}}`,
+		},
+		{
+			name:     "not an object",
+			expected: `{"a":1}`,
+			actual:   `[1]`,
+			patch:    `[1]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := MergePatch([]byte(test.expected), []byte(test.actual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := JSON([]byte(test.patch), patch); d != nil {
+				t.Errorf("Unexpected patch:\n%s", d)
+			}
+			result, err := ApplyMergePatch([]byte(test.expected), patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := JSON([]byte(test.actual), result); d != nil {
+				t.Errorf("Unexpected round trip result:\n%s", d)
+			}
+		})
+	}
+	if _, err := MergePatch(nil, make(chan int)); !errors.As(err, new(*MarshalError)) {
+		t.Errorf("Expected a *MarshalError, got: %v", err)
+	}
+}
+
+func TestMerged(t *testing.T) {
+	base := []byte(`{"id":1,"name":"foo","tags":["a"]}`)
+	t.Run("equal", func(t *testing.T) {
+		expected := &Merged{Base: base, Patch: []byte(`{"name":"bar"}`)}
+		actual := map[string]interface{}{"id": 1, "name": "bar", "tags": []string{"a"}}
+		if d := AsJSON(expected, actual); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("different", func(t *testing.T) {
+		expected := &Merged{Base: base, Patch: []byte(`{"name":"bar"}`)}
+		actual := map[string]interface{}{"id": 1, "name": "baz", "tags": []string{"a"}}
+		d := AsJSONWith(expected, actual, JSONPointers())
+		if d.String() != "/name: \"bar\" → \"baz\"\n" {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("invalid patch", func(t *testing.T) {
+		d := AsJSON(&Merged{Base: base, Patch: []byte(`invalid`)}, nil)
+		if !errors.As(d, new(*MarshalError)) {
+			t.Errorf("Expected a *MarshalError, got: %v", d)
+		}
+	})
+	t.Run("update", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "diff-merge")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		basePath, patchPath := dir+"/base.json", dir+"/patch.json"
+		if e := ioutil.WriteFile(basePath, base, 0666); e != nil {
+			t.Fatal(e)
+		}
+		defer setenv(t, UpdateEnv, "true")()
+		expected := &Merged{Base: &File{Path: basePath}, Patch: &File{Path: patchPath}}
+		actual := map[string]interface{}{"id": 2, "name": "foo"}
+		if d := AsJSON(expected, actual); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		patch, err := ioutil.ReadFile(patchPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := JSON([]byte(`{"id":2,"tags":null}`), patch); d != nil {
+			t.Errorf("Unexpected patch file:\n%s", d)
+		}
+		newBase, err := ioutil.ReadFile(basePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := JSON(base, newBase); d != nil {
+			t.Errorf("Base file was modified:\n%s", d)
+		}
+		if d := AsJSON(expected, actual); d != nil {
+			t.Errorf("Unexpected result after update: %s", d)
+		}
+	})
+	t.Run("update, readers", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "diff-merge")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		patchPath := dir + "/patch.json"
+		func() {
+			defer setenv(t, UpdateEnv, "true")()
+			expected := &Merged{Base: bytes.NewReader(base), Patch: &File{Path: patchPath}}
+			if d := AsJSON(expected, strings.NewReader(`{"id":1,"name":"bar","tags":["a"]}`)); d != nil {
+				t.Fatalf("Unexpected result in update mode: %s", d)
+			}
+		}()
+		patch, err := ioutil.ReadFile(patchPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := JSON([]byte(`{"name":"bar"}`), patch); d != nil {
+			t.Errorf("Unexpected patch file:\n%s", d)
+		}
+		expected := &Merged{Base: bytes.NewReader(base), Patch: &File{Path: patchPath}}
+		if d := AsJSON(expected, strings.NewReader(`{"id":1,"name":"bar","tags":["a"]}`)); d != nil {
+			t.Errorf("Unexpected result after update: %s", d)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"github.com/flimzy/diff/engine"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Option configures a comparison. Options are passed to the *With variants of
+// the comparison functions, such as TextWith and AsJSONWith.
+type Option func(*options)
+
+type options struct {
+	context   int
+	from, to  string
+	fullFile  bool
+	algo      Algorithm
+	intraLine Granularity
+	color     ColorMode
+
+	placeholders bool
+	scrubbers    []Scrubber
+	jsonPointers bool
+	ignores      []ignoreRule
+	unorderedAll bool
+	unordered    []ignoreRule
+	sorts        []sortRule
+
+	sideBySide  bool
+	width       int
+	lineNumbers bool
+	wrap        bool
+
+	// test is the name of the test making the comparison, if known.
+	test string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		context: 2,
+		from:    "expected",
+		to:      "actual",
+		color:   colorUnset,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ContextLines sets the number of unchanged lines to show around each change.
+// The default is 2.
+func ContextLines(n int) Option {
+	return func(o *options) {
+		if n < 0 {
+			n = 0
+		}
+		o.context = n
+	}
+}
+
+// Labels sets the labels of the expected and actual inputs, as shown in the
+// "---" and "+++" header lines of the diff. The defaults are "expected" and
+// "actual". This is useful, for instance, to show the path to a golden file.
+func Labels(expected, actual string) Option {
+	return func(o *options) {
+		o.from = expected
+		o.to = actual
+	}
+}
+
+// FullFile causes the diff to include the entire input as context, in a single
+// hunk, rather than only the lines surrounding each change.
+func FullFile() Option {
+	return func(o *options) {
+		o.fullFile = true
+	}
+}
+
+// contextFor returns the number of context lines to use when comparing inputs
+// of the given lengths.
+func (o *options) contextFor(expected, actual []string) int {
+	if !o.fullFile {
+		return o.context
+	}
+	if len(expected) > len(actual) {
+		return len(expected)
+	}
+	return len(actual)
+}
+
+// Algorithm selects the algorithm used to compute the differences between
+// two sets of lines.
+type Algorithm int
+
+const (
+	// SequenceMatcher uses the Ratcliff/Obershelp algorithm, as implemented
+	// by difflib's SequenceMatcher. This is the default.
+	SequenceMatcher Algorithm = iota
+	// Myers uses Myers' O(ND) algorithm, which produces a minimal diff.
+	Myers
+	// Patience uses the patience diff algorithm, which anchors on lines that
+	// occur exactly once in each input.
+	Patience
+	// Histogram uses the histogram diff algorithm, which anchors on the least
+	// frequently occurring lines. It generally produces the most readable
+	// output for large, repetitive inputs, such as spew dumps and indented
+	// JSON.
+	Histogram
+)
+
+// WithAlgorithm selects the diff algorithm.
+func WithAlgorithm(algo Algorithm) Option {
+	return func(o *options) {
+		o.algo = algo
+	}
+}
+
+// opCodes returns the opcodes to transform a into b.
+func (algo Algorithm) opCodes(a, b []string) []engine.OpCode {
+	switch algo {
+	case Myers:
+		return engine.Myers(a, b)
+	case Patience:
+		return engine.Patience(a, b)
+	case Histogram:
+		return engine.Histogram(a, b)
+	}
+	m := difflib.NewMatcher(a, b)
+	codes := m.GetOpCodes()
+	result := make([]engine.OpCode, len(codes))
+	for i, c := range codes {
+		result[i] = engine.OpCode{Tag: c.Tag, I1: c.I1, I2: c.I2, J1: c.J1, J2: c.J2}
+	}
+	return result
+}
+
+// withTest records the name of the test making the comparison.
+func withTest(name string) Option {
+	return func(o *options) {
+		o.test = name
+	}
+}
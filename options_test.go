@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	const (
+		expected = "one\ntwo\nthree\nfour\nfive\nsix\n"
+		actual   = "one\ntwo\nthree\nFOUR\nfive\nsix\n"
+	)
+	tests := []struct {
+		name   string
+		result *Result
+		want   string
+	}{
+		{
+			name:   "defaults",
+			result: TextWith(expected, actual),
+			want:   "--- expected\n+++ actual\n@@ -2,5 +2,5 @@\n two\n three\n-four\n+FOUR\n five\n six\n",
+		},
+		{
+			name:   "zero context",
+			result: TextWith(expected, actual, ContextLines(0)),
+			want:   "--- expected\n+++ actual\n@@ -4 +4 @@\n-four\n+FOUR\n",
+		},
+		{
+			name:   "negative context",
+			result: TextWith(expected, actual, ContextLines(-3)),
+			want:   "--- expected\n+++ actual\n@@ -4 +4 @@\n-four\n+FOUR\n",
+		},
+		{
+			name:   "labels",
+			result: TextWith(expected, actual, ContextLines(0), Labels("testdata/foo.golden", "got")),
+			want:   "--- testdata/foo.golden\n+++ got\n@@ -4 +4 @@\n-four\n+FOUR\n",
+		},
+		{
+			name:   "full file",
+			result: TextWith(expected, actual, FullFile()),
+			want:   "--- expected\n+++ actual\n@@ -1,6 +1,6 @@\n one\n two\n three\n-four\n+FOUR\n five\n six\n",
+		},
+		{
+			name:   "text slices",
+			result: TextSlicesWith([]string{"foo", "bar"}, []string{"foo", "baz"}, Labels("a", "b")),
+			want:   "--- a\n+++ b\n@@ -1,2 +1,2 @@\n foo\n-bar\n+baz\n",
+		},
+		{
+			name:   "AsJSON",
+			result: AsJSONWith([]int{1, 2, 3}, []int{1, 2, 4}, ContextLines(0)),
+			want:   "--- expected\n+++ actual\n@@ -4 +4 @@\n-    3\n+    4\n",
+		},
+		{
+			name:   "JSON",
+			result: JSONWith([]byte(`[1,2,3]`), []byte(`[1,2,4]`), ContextLines(0)),
+			want:   "--- expected\n+++ actual\n@@ -4 +4 @@\n-    3\n+    4\n",
+		},
+		{
+			name:   "Interface",
+			result: InterfaceWith([]int{1, 2}, []int{1, 3}, ContextLines(0)),
+			want:   "--- expected\n+++ actual\n@@ -3 +3 @@\n-  (int) 2\n+  (int) 3\n",
+		},
+		{
+			name:   "HTTPRequest",
+			result: HTTPRequestWith(httptest.NewRequest("GET", "/", nil), httptest.NewRequest("GET", "/foo", nil), ContextLines(0)),
+			want:   "--- expected\n+++ actual\n@@ -1 +1 @@\n-GET / HTTP/1.1\r\n+GET /foo HTTP/1.1\r\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if d := Text(test.want, test.result.String()); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestWithAlgorithm(t *testing.T) {
+	type item struct {
+		Name  string
+		Value int
+	}
+	expected := []item{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}}
+	actual := []item{{"a", 1}, {"c", 3}, {"d", 4}, {"e", 5}}
+	want := `--- expected
++++ actual
+@@ -5,8 +5,4 @@
+   },
+   (diff.item) {
+-    Name: (string) (len=1) "b",
+-    Value: (int) 2
+-  },
+-  (diff.item) {
+     Name: (string) (len=1) "c",
+     Value: (int) 3
+@@ -15,4 +11,8 @@
+     Name: (string) (len=1) "d",
+     Value: (int) 4
++  },
++  (diff.item) {
++    Name: (string) (len=1) "e",
++    Value: (int) 5
+   }
+ }
+`
+	for _, algo := range []Algorithm{Myers, Patience, Histogram} {
+		result := InterfaceWith(expected, actual, WithAlgorithm(algo))
+		if d := Text(want, result.String()); d != nil {
+			t.Errorf("Unexpected result for algorithm %d:\n%s", algo, d)
+		}
+	}
+}
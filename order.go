@@ -0,0 +1,268 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// UnorderedArrays causes AsJSON and JSON to compare arrays as multisets,
+// without regard to the order of their elements. If paths are given, only the
+// arrays at those JSON Pointers are affected, which may contain "*" tokens, as
+// for IgnorePath. Otherwise, all arrays are.
+//
+// So that the rendered diff lines up matching elements, the elements of the
+// actual array are reordered to follow the expected array. Actual elements
+// without an equal expected element take the places of the unmatched expected
+// elements, in order, and any remaining ones follow. Values ignored with
+// IgnorePointer, IgnorePath or IgnoreKey are disregarded when matching
+// elements. In update mode (see Updating), the golden file is written in this
+// order.
+//
+// The JSON Pointers of the changes reported by Result.Changes, and rendered by
+// JSONPointers, index such arrays in this aligned order, rather than in the
+// order of the actual document. For example, comparing [1,2,3] to [3,2,4]
+// reports "/0: 1 → 4", as 4 takes the place of the unmatched 1.
+func UnorderedArrays(paths ...string) Option {
+	return func(o *options) {
+		if len(paths) == 0 {
+			o.unorderedAll = true
+		}
+		for _, p := range paths {
+			o.unordered = append(o.unordered, ignoreRule{tokens: parsePointer(p), wildcard: true})
+		}
+	}
+}
+
+// SortArray causes AsJSON and JSON to sort the elements of the arrays at path,
+// which may contain "*" tokens, as for IgnorePath, in both documents, by the
+// value at the JSON Pointer key within each element. For example, to compare
+// users by ID, regardless of their order:
+//
+//	diff.AsJSONWith(expected, actual, diff.SortArray("/users", "/id"))
+//
+// Numbers and strings sort in their natural order. Elements which lack the key
+// sort first, and elements with equal keys keep their relative order. The
+// JSON Pointers of reported changes index such arrays in their sorted order,
+// in both documents.
+func SortArray(path, key string) Option {
+	return func(o *options) {
+		o.sorts = append(o.sorts, sortRule{
+			path: ignoreRule{tokens: parsePointer(path), wildcard: true},
+			key:  parsePointer(key),
+		})
+	}
+}
+
+// sortRule identifies arrays to sort, and the key to sort them by.
+type sortRule struct {
+	path ignoreRule
+	key  []string
+}
+
+// orderJSON sorts and aligns the arrays of the indented JSON documents
+// expected and actual, as returned by marshal, as selected by UnorderedArrays
+// and SortArray. If either document cannot be decoded, both are returned
+// unchanged.
+func (o *options) orderJSON(expected, actual []byte) ([]byte, []byte) {
+	if !o.unorderedAll && len(o.unordered) == 0 && len(o.sorts) == 0 {
+		return expected, actual
+	}
+	var e, a interface{}
+	if json.Unmarshal(expected, &e) != nil || json.Unmarshal(actual, &a) != nil {
+		return expected, actual
+	}
+	e = o.sortArrays(nil, e)
+	a = o.alignArrays(nil, e, o.sortArrays(nil, a))
+	expectedJSON, err := json.MarshalIndent(e, "", "    ")
+	if err != nil {
+		return expected, actual
+	}
+	actualJSON, err := json.MarshalIndent(a, "", "    ")
+	if err != nil {
+		return expected, actual
+	}
+	return expectedJSON, actualJSON
+}
+
+// sortArrays returns v with the arrays selected by SortArray sorted.
+func (o *options) sortArrays(path []string, v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			result[k] = o.sortArrays(append(path[:len(path):len(path)], k), child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(t))
+		for i, child := range t {
+			result[i] = o.sortArrays(append(path[:len(path):len(path)], strconv.Itoa(i)), child)
+		}
+		for _, r := range o.sorts {
+			if r.path.match(path, true) {
+				sort.SliceStable(result, func(i, j int) bool {
+					return jsonSortKey(lookupPointer(result[i], r.key)).less(jsonSortKey(lookupPointer(result[j], r.key)))
+				})
+				break
+			}
+		}
+		return result
+	}
+	return v
+}
+
+// alignArrays returns actual with the elements of the arrays selected by
+// UnorderedArrays reordered to follow expected.
+func (o *options) alignArrays(path []string, expected, actual interface{}) interface{} {
+	switch a := actual.(type) {
+	case map[string]interface{}:
+		e, ok := expected.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+		result := make(map[string]interface{}, len(a))
+		for k, child := range a {
+			result[k] = child
+			if ev, ok := e[k]; ok {
+				result[k] = o.alignArrays(append(path[:len(path):len(path)], k), ev, child)
+			}
+		}
+		return result
+	case []interface{}:
+		e, ok := expected.([]interface{})
+		if !ok {
+			return actual
+		}
+		if o.unorderedAll || o.isUnordered(path) {
+			a = o.matchElements(path, e, a)
+		}
+		result := make([]interface{}, len(a))
+		for i, child := range a {
+			result[i] = child
+			if i < len(e) {
+				result[i] = o.alignArrays(append(path[:len(path):len(path)], strconv.Itoa(i)), e[i], child)
+			}
+		}
+		return result
+	}
+	return actual
+}
+
+func (o *options) isUnordered(path []string) bool {
+	for _, r := range o.unordered {
+		if r.match(path, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchElements returns the elements of actual, reordered to follow expected,
+// as described by UnorderedArrays. Elements are equal if they are equal once
+// their own unordered arrays are aligned.
+func (o *options) matchElements(path []string, expected, actual []interface{}) []interface{} {
+	matched := make([]interface{}, len(expected))
+	found := make([]bool, len(expected))
+	used := make([]bool, len(actual))
+	for i, ev := range expected {
+		p := append(path[:len(path):len(path)], strconv.Itoa(i))
+		for j, av := range actual {
+			if !used[j] && o.matches(p, ev, av) {
+				matched[i], found[i], used[j] = av, true, true
+				break
+			}
+		}
+	}
+	var rest []interface{}
+	for j, av := range actual {
+		if !used[j] {
+			rest = append(rest, av)
+		}
+	}
+	result := make([]interface{}, 0, len(actual))
+	for i := range expected {
+		switch {
+		case found[i]:
+			result = append(result, matched[i])
+		case len(rest) > 0:
+			result = append(result, rest[0])
+			rest = rest[1:]
+		}
+	}
+	return append(result, rest...)
+}
+
+// matches reports whether the actual element av equals the expected element
+// ev, once the unordered arrays of av are aligned, and any ignored values are
+// removed from both.
+func (o *options) matches(path []string, ev, av interface{}) bool {
+	av = o.alignArrays(path, ev, av)
+	if len(o.ignores) > 0 {
+		ev, av = o.filter(path, ev), o.filter(path, av)
+	}
+	return reflect.DeepEqual(ev, av)
+}
+
+// lookupPointer returns the value at the JSON Pointer tokens within v, and
+// whether it exists.
+func lookupPointer(v interface{}, tokens []string) (interface{}, bool) {
+	for _, token := range tokens {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[token]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// sortKey orders JSON values: missing values first, then null, booleans,
+// numbers, strings, and finally arrays and objects, by their JSON encoding.
+type sortKey struct {
+	rank int
+	num  float64
+	str  string
+}
+
+func (k sortKey) less(other sortKey) bool {
+	if k.rank != other.rank {
+		return k.rank < other.rank
+	}
+	if k.num != other.num {
+		return k.num < other.num
+	}
+	return k.str < other.str
+}
+
+// jsonSortKey returns the sort key of v, where ok reports whether v exists.
+func jsonSortKey(v interface{}, ok bool) sortKey {
+	if !ok {
+		return sortKey{}
+	}
+	switch t := v.(type) {
+	case nil:
+		return sortKey{rank: 1}
+	case bool:
+		if t {
+			return sortKey{rank: 2, num: 1}
+		}
+		return sortKey{rank: 2}
+	case float64:
+		return sortKey{rank: 3, num: t}
+	case string:
+		return sortKey{rank: 4, str: t}
+	}
+	return sortKey{rank: 5, str: jsonValue(v)}
+}
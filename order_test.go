@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestUnorderedArrays(t *testing.T) {
+	t.Run("ordered by default", func(t *testing.T) {
+		if d := JSON([]byte(`[1,2,3]`), []byte(`[3,1,2]`)); d == nil {
+			t.Error("Expected a difference")
+		}
+	})
+	t.Run("all arrays", func(t *testing.T) {
+		expected := []byte(`{"a":[1,2,[3,4]],"b":[{"c":["x","y"]},{"c":[]}]}`)
+		actual := []byte(`{"a":[[4,3],2,1],"b":[{"c":[]},{"c":["y","x"]}]}`)
+		if d := JSONWith(expected, actual, UnorderedArrays()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("multiset", func(t *testing.T) {
+		if d := JSONWith([]byte(`[1,1,2]`), []byte(`[1,2,2]`), UnorderedArrays()); d == nil {
+			t.Error("Expected a difference in multiplicity")
+		}
+	})
+	t.Run("selected paths", func(t *testing.T) {
+		expected := []byte(`{"tags":["a","b"],"items":[{"ids":[1,2]}],"order":[1,2]}`)
+		actual := []byte(`{"tags":["b","a"],"items":[{"ids":[2,1]}],"order":[2,1]}`)
+		d := JSONWith(expected, actual, UnorderedArrays("/tags", "/items/*/ids"), JSONPointers())
+		want := "/order/0: 1 → 2\n/order/1: 2 → 1\n"
+		if d.String() != want {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("aligned rendering", func(t *testing.T) {
+		expected := []interface{}{"a", "b", "c", "d"}
+		actual := []interface{}{"d", "x", "a", "c"}
+		d := AsJSONWith(expected, actual, UnorderedArrays(), JSONPointers())
+		if d.String() != "/1: \"b\" → \"x\"\n" {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+		d = AsJSONWith(expected, []interface{}{"d", "c", "a", "b", "e"}, UnorderedArrays(), JSONPointers())
+		if d.String() != "/4: added \"e\"\n" {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("pointers index the aligned order", func(t *testing.T) {
+		// 4 is at index 2 of the actual document, but takes the place of
+		// the unmatched 1, at index 0 of the aligned array.
+		d := JSONWith([]byte(`[1,2,3]`), []byte(`[3,2,4]`), UnorderedArrays(), JSONPointers())
+		if d.String() != "/0: 1 → 4\n" {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+		want := []Change{{Path: "/0", Kind: Replaced, Old: float64(1), New: float64(4)}}
+		if d := Interface(want, d.Changes()); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestSortArray(t *testing.T) {
+	expected := []byte(`{"users":[{"id":1,"name":"foo"},{"id":2,"name":"bar"},{"id":10,"name":"baz"}]}`)
+	t.Run("equal", func(t *testing.T) {
+		actual := []byte(`{"users":[{"id":10,"name":"baz"},{"id":1,"name":"foo"},{"id":2,"name":"bar"}]}`)
+		if d := JSONWith(expected, actual, SortArray("/users", "/id")); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("different", func(t *testing.T) {
+		actual := []byte(`{"users":[{"id":10,"name":"baz"},{"id":2,"name":"qux"},{"id":1,"name":"foo"}]}`)
+		d := JSONWith(expected, actual, SortArray("/users", "/id"), JSONPointers())
+		if d.String() != "/users/1/name: \"bar\" → \"qux\"\n" {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("wildcard path", func(t *testing.T) {
+		expected := []interface{}{[]interface{}{map[string]interface{}{"k": "a"}, map[string]interface{}{"k": "b"}}}
+		actual := []interface{}{[]interface{}{map[string]interface{}{"k": "b"}, map[string]interface{}{"k": "a"}}}
+		if d := AsJSONWith(expected, actual, SortArray("/*", "/k")); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestJSONSortKey(t *testing.T) {
+	ordered := []interface{}{nil, false, true, -1.5, 2.0, 10.0, "10", "9", "a", []interface{}{}, map[string]interface{}{}}
+	if !jsonSortKey(nil, false).less(jsonSortKey(nil, true)) {
+		t.Error("Expected missing values to sort first")
+	}
+	for i := 1; i < len(ordered); i++ {
+		prev, cur := jsonSortKey(ordered[i-1], true), jsonSortKey(ordered[i], true)
+		if !prev.less(cur) || cur.less(prev) {
+			t.Errorf("Expected %v to sort before %v", ordered[i-1], ordered[i])
+		}
+	}
+	if _, ok := lookupPointer(map[string]interface{}{"a": []interface{}{1}}, []string{"a", "1"}); ok {
+		t.Error("Expected out of range index not to be found")
+	}
+	if v, ok := lookupPointer(map[string]interface{}{"a": []interface{}{1}}, []string{"a", "0"}); !ok || v != 1 {
+		t.Errorf("Unexpected value: %v, %t", v, ok)
+	}
+}
+
+func TestUnorderedArraysUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-unordered")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/foo.json"
+	if e := ioutil.WriteFile(path, []byte(`["a","b","c"]`), 0666); e != nil {
+		t.Fatal(e)
+	}
+	defer setenv(t, UpdateEnv, "true")()
+	if d := JSONWith(&File{Path: path}, []byte(`["c","x","a"]`), UnorderedArrays()); d != nil {
+		t.Fatalf("Unexpected result in update mode: %s", d)
+	}
+	if d := Text("[\n    \"a\",\n    \"x\",\n    \"c\"\n]", &File{Path: path}); d != nil {
+		t.Errorf("Unexpected golden file content:\n%s", d)
+	}
+	t.Run("ignored members", func(t *testing.T) {
+		path := dir + "/ignored.json"
+		if e := ioutil.WriteFile(path, []byte(`[{"id":1,"name":"a"},{"id":2,"name":"b"}]`), 0666); e != nil {
+			t.Fatal(e)
+		}
+		actual := []byte(`[{"id":9,"name":"b"},{"id":8,"name":"c"}]`)
+		if d := JSONWith(&File{Path: path}, actual, UnorderedArrays(), IgnoreKey("id")); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		expected := `[{"id":8,"name":"c"},{"id":9,"name":"b"}]`
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := JSON([]byte(expected), content); d != nil {
+			t.Errorf("Unexpected golden file content:\n%s", d)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Operation is a single operation of an RFC 6902 JSON Patch.
+type Operation struct {
+	// Op is one of "add", "remove", "replace" or "move".
+	Op string `json:"op"`
+	// From is the JSON Pointer to the value to move, for "move" operations.
+	From string `json:"from,omitempty"`
+	// Path is the JSON Pointer to the target of the operation.
+	Path string `json:"path"`
+	// Value is the value to add, or to replace the target with, as decoded
+	// by encoding/json.
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON marshals op, including its value for "add" and "replace"
+// operations, even if the value is null.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	if op.Op != "add" && op.Op != "replace" {
+		type operation Operation
+		return json.Marshal(operation(op))
+	}
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{Op: op.Op, Path: op.Path, Value: op.Value})
+}
+
+// Patch is an RFC 6902 JSON Patch, which marshals to a JSON array of
+// operations.
+type Patch []Operation
+
+// JSONPatch returns an RFC 6902 JSON Patch which transforms the expected JSON
+// document into the actual one. expected and actual are interpreted as by
+// AsJSON. An empty Patch means that the documents are equal.
+//
+// Values which move between members of objects are reported by "move"
+// operations. Within arrays, elements are compared by index, so that
+// elements are added or removed at the end of the array, in descending order
+// of index for removals, so that each operation applies to the result of the
+// previous one.
+func JSONPatch(expected, actual interface{}) (Patch, error) {
+	e, err := decodeJSON(SideExpected, expected)
+	if err != nil {
+		return nil, err
+	}
+	a, err := decodeJSON(SideActual, actual)
+	if err != nil {
+		return nil, err
+	}
+	p := Patch{}
+	var moves []int
+	diffPatch("", true, e, a, &p, &moves)
+	return p.withMoves(moves), nil
+}
+
+// decodeJSON returns the decoded JSON document for i, interpreted as by
+// AsJSON.
+func decodeJSON(side string, i interface{}) (interface{}, error) {
+	buf, err := marshal(i)
+	if err != nil {
+		return nil, &MarshalError{Side: side, Op: "marshal", Err: err}
+	}
+	var x interface{}
+	if err := json.Unmarshal(buf, &x); err != nil {
+		return nil, &MarshalError{Side: side, Op: "unmarshal", Err: err}
+	}
+	return x, nil
+}
+
+// diffPatch appends the operations which transform expected into actual to p.
+// The indexes within p of "add" and "remove" operations, which may be
+// converted to moves because their paths consist of object members only, are
+// appended to moves.
+func diffPatch(path string, objectOnly bool, expected, actual interface{}, p *Patch, moves *[]int) {
+	add := func(op Operation) {
+		if objectOnly {
+			*moves = append(*moves, len(*p))
+		}
+		*p = append(*p, op)
+	}
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ev, inE := e[k]
+			av, inA := a[k]
+			member := path + "/" + escapePointer(k)
+			switch {
+			case !inA:
+				add(Operation{Op: "remove", Path: member, Value: ev})
+			case !inE:
+				add(Operation{Op: "add", Path: member, Value: av})
+			default:
+				diffPatch(member, objectOnly, ev, av, p, moves)
+			}
+		}
+		return
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(e) && i < len(a); i++ {
+			diffPatch(path+"/"+strconv.Itoa(i), false, e[i], a[i], p, moves)
+		}
+		for i := len(e); i < len(a); i++ {
+			*p = append(*p, Operation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: a[i]})
+		}
+		for i := len(e) - 1; i >= len(a); i-- {
+			*p = append(*p, Operation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		return
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		*p = append(*p, Operation{Op: "replace", Path: path, Value: actual})
+	}
+}
+
+// withMoves returns p with each pair of a removed and an added value which are
+// equal, among the candidates in moves, replaced by a single "move" operation
+// in place of the "add". The values of "remove" operations, which are recorded
+// only to detect moves, are cleared.
+func (p Patch) withMoves(moves []int) Patch {
+	removed := make(map[int]bool)
+	for _, add := range moves {
+		if p[add].Op != "add" {
+			continue
+		}
+		for _, remove := range moves {
+			op := p[remove]
+			if op.Op == "remove" && !removed[remove] && reflect.DeepEqual(op.Value, p[add].Value) {
+				removed[remove] = true
+				p[add] = Operation{Op: "move", From: op.Path, Path: p[add].Path}
+				break
+			}
+		}
+	}
+	result := make(Patch, 0, len(p)-len(removed))
+	for i, op := range p {
+		if removed[i] {
+			continue
+		}
+		if op.Op == "remove" {
+			op.Value = nil
+		}
+		result = append(result, op)
+	}
+	return result
+}
@@ -0,0 +1,220 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		patch            string
+	}{
+		{
+			name:     "equal",
+			expected: `{"a":[1,{"b":2}]}`,
+			actual:   `{"a":[1,{"b":2}]}`,
+			patch:    `[]`,
+		},
+		{
+			name:     "root",
+			expected: `[1]`,
+			actual:   `{"a":1}`,
+			patch:    `[{"op":"replace","path":"","value":{"a":1}}]`,
+		},
+		{
+			name:     "members",
+			expected: `{"a":1,"b":2,"c":{"d":true}}`,
+			actual:   `{"a":1,"b":3,"c":{"e":null}}`,
+			patch:    `[{"op":"replace","path":"/b","value":3},{"op":"remove","path":"/c/d"},{"op":"add","path":"/c/e","value":null}]`,
+		},
+		{
+			name:     "array removals",
+			expected: `{"items":[1,2,3,4]}`,
+			actual:   `{"items":[1,5]}`,
+			patch:    `[{"op":"replace","path":"/items/1","value":5},{"op":"remove","path":"/items/3"},{"op":"remove","path":"/items/2"}]`,
+		},
+		{
+			name:     "array additions",
+			expected: `[{"a":1}]`,
+			actual:   `[{"a":2},"x","y"]`,
+			patch:    `[{"op":"replace","path":"/0/a","value":2},{"op":"add","path":"/1","value":"x"},{"op":"add","path":"/2","value":"y"}]`,
+		},
+		{
+			name:     "move",
+			expected: `{"old":{"x":[1,2]},"keep":{"a":1}}`,
+			actual:   `{"keep":{"a":1,"new":{"x":[1,2]}}}`,
+			patch:    `[{"op":"move","from":"/old","path":"/keep/new"}]`,
+		},
+		{
+			name:     "no move within arrays",
+			expected: `[{"a":1}]`,
+			actual:   `[{"b":1}]`,
+			patch:    `[{"op":"remove","path":"/0/a"},{"op":"add","path":"/0/b","value":1}]`,
+		},
+		{
+			name:     "escaped",
+			expected: `{"a/b":1,"c~d":2}`,
+			actual:   `{"a/b":2}`,
+			patch:    `[{"op":"replace","path":"/a~1b","value":2},{"op":"remove","path":"/c~0d"}]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := JSONPatch([]byte(test.expected), json.RawMessage(test.actual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := AsJSON([]byte(test.patch), p); d != nil {
+				t.Errorf("Unexpected patch:\n%s", d)
+			}
+			var doc interface{}
+			if e := json.Unmarshal([]byte(test.expected), &doc); e != nil {
+				t.Fatal(e)
+			}
+			result, err := applyPatch(doc, p)
+			if err != nil {
+				t.Fatalf("Failed to apply patch: %s", err)
+			}
+			if d := AsJSON([]byte(test.actual), result); d != nil {
+				t.Errorf("Unexpected round trip result:\n%s", d)
+			}
+		})
+	}
+}
+
+func TestJSONPatchInputs(t *testing.T) {
+	p, err := JSONPatch(map[string]int{"a": 1}, strings.NewReader(`{"a":2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Interface(Patch{{Op: "replace", Path: "/a", Value: 2.0}}, p); d != nil {
+		t.Error(d)
+	}
+	_, err = JSONPatch(make(chan int), nil)
+	var marshalErr *MarshalError
+	if !errors.As(err, &marshalErr) || marshalErr.Side != SideExpected {
+		t.Errorf("Expected a *MarshalError for the expected side, got: %v", err)
+	}
+	_, err = JSONPatch(nil, []byte("invalid"))
+	if !errors.As(err, &marshalErr) || marshalErr.Side != SideActual {
+		t.Errorf("Expected a *MarshalError for the actual side, got: %v", err)
+	}
+}
+
+// applyPatch applies the RFC 6902 patch p to doc.
+func applyPatch(doc interface{}, p Patch) (interface{}, error) {
+	var err error
+	for _, op := range p {
+		switch op.Op {
+		case "add":
+			doc, err = patchAdd(doc, op.Path, op.Value)
+		case "remove":
+			doc, _, err = patchRemove(doc, op.Path)
+		case "replace":
+			if doc, _, err = patchRemove(doc, op.Path); err == nil {
+				doc, err = patchAdd(doc, op.Path, op.Value)
+			}
+		case "move":
+			var v interface{}
+			if doc, v, err = patchRemove(doc, op.From); err == nil {
+				doc, err = patchAdd(doc, op.Path, v)
+			}
+		default:
+			err = fmt.Errorf("unsupported op %q", op.Op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// patchAdd adds v at pointer within doc, and returns the new document.
+func patchAdd(doc interface{}, pointer string, v interface{}) (interface{}, error) {
+	return patchAt(doc, parsePointer(pointer), func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = v
+			return p, nil
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i > len(p) {
+				return nil, fmt.Errorf("invalid index %q", token)
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = v
+			return p, nil
+		}
+		return nil, fmt.Errorf("cannot add to %T", parent)
+	}, v)
+}
+
+// patchRemove removes the value at pointer within doc, and returns the new
+// document and the removed value.
+func patchRemove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	var removed interface{}
+	doc, err := patchAt(doc, parsePointer(pointer), func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			v, ok := p[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			removed = v
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(p) {
+				return nil, fmt.Errorf("invalid index %q", token)
+			}
+			removed = p[i]
+			return append(p[:i:i], p[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove from %T", parent)
+	}, nil)
+	if len(parsePointer(pointer)) == 0 {
+		removed = doc
+	}
+	return doc, removed, err
+}
+
+// patchAt applies fn to the parent of the value identified by tokens, and
+// returns the new document. If tokens is empty, root replaces the document.
+func patchAt(doc interface{}, tokens []string, fn func(parent interface{}, token string) (interface{}, error), root interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return root, nil
+	}
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		child, err := patchAt(d[tokens[0]], tokens[1:], fn, root)
+		if err != nil {
+			return nil, err
+		}
+		d[tokens[0]] = child
+		return d, nil
+	case []interface{}:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i >= len(d) {
+			return nil, fmt.Errorf("invalid index %q", tokens[0])
+		}
+		child, err := patchAt(d[i], tokens[1:], fn, root)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = child
+		return d, nil
+	}
+	return nil, fmt.Errorf("cannot traverse %s", reflect.TypeOf(doc))
+}
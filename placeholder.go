@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Placeholders enables placeholders in the expected value of Text, AsJSON,
+// JSON, HTTPRequest and HTTPResponse comparisons, and their *With variants.
+// A line of expected text which contains placeholders matches any line of
+// actual text which fits its pattern. The supported placeholders are:
+//
+//	{{uuid}}         a UUID, such as 123e4567-e89b-12d3-a456-426614174000
+//	{{rfc1123}}      a time in RFC 1123 format, as used by HTTP Date headers
+//	{{rfc3339}}      a time in RFC 3339 format, with optional fractional seconds
+//	{{int}}          a decimal integer, optionally negative
+//	{{any}}          any text
+//	{{regex "re"}}   text matching the regular expression re, which may be
+//	                 quoted with double quotes or backticks
+//
+// A placeholder enclosed in double quotes, such as "{{int}}", also matches an
+// unquoted value, so that JSON goldens remain valid JSON. Any other text
+// between "{{" and "}}" is matched literally. A regex placeholder whose
+// expression cannot be compiled causes the comparison to fail with a
+// *PlaceholderError.
+//
+// In update mode (see Updating), lines which still match their expected
+// pattern are written with their placeholders intact.
+func Placeholders() Option {
+	return func(o *options) {
+		o.placeholders = true
+	}
+}
+
+var placeholderPatterns = map[string]string{
+	"uuid":    `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"rfc1123": `(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun), \d{2} (?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4} \d{2}:\d{2}:\d{2} (?:[A-Z]{3}|[+-]\d{4})`,
+	"rfc3339": `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})`,
+	"int":     `-?\d+`,
+	"any":     `.*`,
+}
+
+var placeholderRE = regexp.MustCompile("\"?\\{\\{\\s*(?:([a-z0-9]+)|regex\\s+(\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`))\\s*\\}\\}\"?")
+
+// compileTemplate returns a regular expression matching the lines which fit
+// the template line, or nil if line contains no placeholders.
+func compileTemplate(line string) (*regexp.Regexp, error) {
+	matches := placeholderRE.FindAllStringSubmatchIndex(line, -1)
+	pattern := &strings.Builder{}
+	pattern.WriteString("^")
+	var last int
+	var found bool
+	for _, m := range matches {
+		full := line[m[0]:m[1]]
+		var p string
+		switch {
+		case m[2] >= 0:
+			p = placeholderPatterns[line[m[2]:m[3]]]
+		default:
+			re, err := unquoteRegex(line[m[4]:m[5]])
+			if err != nil {
+				return nil, &PlaceholderError{Placeholder: full, Err: err}
+			}
+			p = re
+		}
+		if p == "" {
+			continue
+		}
+		found = true
+		p = "(?:" + p + ")"
+		quoted := strings.HasPrefix(full, `"`) && strings.HasSuffix(full, `"`) && len(full) > 1
+		switch {
+		case quoted:
+			p = `(?:"` + p + `"|` + p + `)`
+		case strings.HasPrefix(full, `"`):
+			p = `"` + p
+		case strings.HasSuffix(full, `"`):
+			p += `"`
+		}
+		pattern.WriteString(regexp.QuoteMeta(line[last:m[0]]))
+		pattern.WriteString(p)
+		last = m[1]
+	}
+	if !found {
+		return nil, nil
+	}
+	pattern.WriteString(regexp.QuoteMeta(line[last:]))
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
+
+// unquoteRegex returns the regular expression from the quoted argument of a
+// regex placeholder, after checking that it compiles.
+func unquoteRegex(quoted string) (string, error) {
+	re, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", err
+	}
+	if _, err := regexp.Compile(re); err != nil {
+		return "", err
+	}
+	return re, nil
+}
+
+// fillPlaceholders returns actual with each line which matches a template line
+// of expected, as aligned by the diff algorithm, replaced by the template line.
+// The actual line endings are preserved.
+func fillPlaceholders(expected, actual string, o *options) (string, error) {
+	if !o.placeholders {
+		return actual, nil
+	}
+	exp := strings.SplitAfter(expected, "\n")
+	act := strings.SplitAfter(actual, "\n")
+	templates := make([]*regexp.Regexp, len(exp))
+	var found bool
+	for i, line := range exp {
+		re, err := compileTemplate(trimEOL(line))
+		if err != nil {
+			return actual, err
+		}
+		if templates[i] = re; re != nil {
+			found = true
+		}
+	}
+	if !found {
+		return actual, nil
+	}
+	for _, c := range o.algo.opCodes(exp, act) {
+		if c.Tag != 'r' {
+			continue
+		}
+		i := c.I1
+		for j := c.J1; j < c.J2 && i < c.I2; j++ {
+			line := trimEOL(act[j])
+			for k := i; k < c.I2; k++ {
+				if templates[k] != nil && templates[k].MatchString(line) {
+					act[j] = trimEOL(exp[k]) + act[j][len(line):]
+					i = k + 1
+					break
+				}
+			}
+		}
+	}
+	return strings.Join(act, ""), nil
+}
+
+// trimEOL returns line without its line ending.
+func trimEOL(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
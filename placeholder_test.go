@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		matches  []string
+		rejects  []string
+	}{
+		{
+			template: "no placeholders",
+		},
+		{
+			template: "unknown {{foo}}",
+		},
+		{
+			template: "id: {{uuid}}",
+			matches:  []string{"id: 123e4567-e89b-12d3-a456-426614174000"},
+			rejects:  []string{"id: 123e4567", "id: 123e4567-e89b-12d3-a456-426614174000 "},
+		},
+		{
+			template: "Date: {{rfc1123}}",
+			matches:  []string{"Date: Tue, 22 Jan 2019 18:44:09 GMT", "Date: Mon, 02 Jan 2006 15:04:05 -0700"},
+			rejects:  []string{"Date: 2019-01-22T18:44:09Z"},
+		},
+		{
+			template: "time={{rfc3339}}",
+			matches:  []string{"time=2019-01-22T18:44:09Z", "time=2019-01-22T18:44:09.123+01:00"},
+			rejects:  []string{"time=2019-01-22"},
+		},
+		{
+			template: "{{int}} items (.*)",
+			matches:  []string{"3 items (.*)", "-12 items (.*)"},
+			rejects:  []string{"3 items (x)", "three items (.*)"},
+		},
+		{
+			template: "[{{any}}]",
+			matches:  []string{"[]", "[foo bar]"},
+			rejects:  []string{"foo"},
+		},
+		{
+			template: `path: {{regex "/tmp/[a-z]+[0-9]+"}}/{{ regex ` + "`\\d+`" + ` }}`,
+			matches:  []string{"path: /tmp/foo123/4"},
+			rejects:  []string{"path: /tmp/foo/4", "path: /tmp/foo123/x"},
+		},
+		{
+			template: `"count": "{{int}}",`,
+			matches:  []string{`"count": 5,`, `"count": "5",`},
+			rejects:  []string{`"count": "5,`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			re, err := compileTemplate(test.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if re == nil {
+				if len(test.matches) > 0 {
+					t.Fatal("Expected a template")
+				}
+				return
+			}
+			for _, m := range test.matches {
+				if !re.MatchString(m) {
+					t.Errorf("Expected %q to match", m)
+				}
+			}
+			for _, r := range test.rejects {
+				if re.MatchString(r) {
+					t.Errorf("Expected %q not to match", r)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		if d := Text("id: {{int}}", "id: 3"); d == nil {
+			t.Error("Expected a difference without Placeholders")
+		}
+	})
+	t.Run("text", func(t *testing.T) {
+		expected := "foo\nid: {{int}}\nbar\n"
+		if d := TextWith(expected, "foo\nid: 3\nbar\n", Placeholders()); d != nil {
+			t.Error(d)
+		}
+		d := TextWith(expected, "foo\nid: x\nbaz\n", Placeholders())
+		want := "--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n foo\n-id: {{int}}\n-bar\n+id: x\n+baz\n"
+		if d == nil || d.String() != want {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+		d = TextWith(expected, "foo\nid: 3\nbaz\n", Placeholders())
+		want = "--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n foo\n id: {{int}}\n-bar\n+baz\n"
+		if d == nil || d.String() != want {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		expected := []byte(`{"id":"{{uuid}}","count":"{{int}}","name":"foo"}`)
+		actual := map[string]interface{}{
+			"id":    "123e4567-e89b-12d3-a456-426614174000",
+			"count": 3,
+			"name":  "foo",
+		}
+		if d := AsJSONWith(expected, actual, Placeholders()); d != nil {
+			t.Error(d)
+		}
+		if d := JSONWith(expected, []byte(`{"id":"x","count":3,"name":"foo"}`), Placeholders()); d == nil {
+			t.Error("Expected a difference")
+		}
+	})
+	t.Run("http response", func(t *testing.T) {
+		expected := "HTTP/1.1 200 OK\r\nContent-Length: 11\r\nContent-Type: application/json\r\nDate: {{rfc1123}}\r\n\r\n{\"ok\":true}"
+		actual := &http.Response{
+			StatusCode:    200,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			ContentLength: 11,
+			Header: http.Header{
+				"Content-Type": {"application/json"},
+				"Date":         {"Tue, 22 Jan 2019 18:44:09 GMT"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(`{"ok":true}`)),
+		}
+		if d := HTTPResponseWith(expected, actual, Placeholders()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("invalid regex", func(t *testing.T) {
+		d := TextWith("foo\nid: {{regex \"[0-9\"}}\n", "foo\nid: 3\n", Placeholders())
+		var placeholderErr *PlaceholderError
+		if !errors.As(d.Err(), &placeholderErr) {
+			t.Fatalf("Expected *PlaceholderError, got: %v", d)
+		}
+		if placeholderErr.Placeholder != `{{regex "[0-9"}}` {
+			t.Errorf("Unexpected placeholder: %s", placeholderErr.Placeholder)
+		}
+		expected := "invalid placeholder {{regex \"[0-9\"}}: error parsing regexp: missing closing ]: `[0-9`"
+		if d := Text(expected, d.String()); d != nil {
+			t.Error(d)
+		}
+	})
+}
+
+func TestPlaceholdersUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-placeholders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/foo.golden"
+	if e := ioutil.WriteFile(path, []byte("id: {{int}}\ntime: {{rfc3339}}\nname: foo\n"), 0666); e != nil {
+		t.Fatal(e)
+	}
+	defer setenv(t, UpdateEnv, "true")()
+	if d := TextWith(&File{Path: path}, "id: 3\ntime: now\nname: bar\n", Placeholders()); d != nil {
+		t.Fatalf("Unexpected result in update mode: %s", d)
+	}
+	if d := Text("id: {{int}}\ntime: now\nname: bar\n", &File{Path: path}); d != nil {
+		t.Errorf("Unexpected golden file content:\n%s", d)
+	}
+	t.Run("invalid regex", func(t *testing.T) {
+		invalid := "id: {{regex `(`}}\n"
+		if e := ioutil.WriteFile(path, []byte(invalid), 0666); e != nil {
+			t.Fatal(e)
+		}
+		if d := TextWith(&File{Path: path}, "id: 3\n", Placeholders()); d.Err() == nil {
+			t.Fatalf("Expected an error, got: %v", d)
+		}
+		if d := Text(invalid, &File{Path: path}); d != nil {
+			t.Errorf("Expected the golden file to be left intact:\n%s", d)
+		}
+	})
+}
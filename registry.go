@@ -0,0 +1,318 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GoldenStatus describes the effect of update mode on a golden file.
+type GoldenStatus string
+
+// The possible values of GoldenStatus.
+const (
+	GoldenCreated   GoldenStatus = "created"
+	GoldenModified  GoldenStatus = "modified"
+	GoldenUnchanged GoldenStatus = "unchanged"
+)
+
+// GoldenUpdate records the effect of update mode on a single golden file.
+type GoldenUpdate struct {
+	Path   string       `json:"path"`
+	Status GoldenStatus `json:"status"`
+	// BytesBefore is the size of the file before it was first updated during
+	// this run, or 0 if it did not exist.
+	BytesBefore int64 `json:"bytes_before"`
+	// BytesAfter is the size of the file after it was last updated during
+	// this run.
+	BytesAfter int64 `json:"bytes_after"`
+	// Test is the name of the test which first compared against the file, if
+	// it could be determined.
+	Test string `json:"test,omitempty"`
+}
+
+// registry tracks the golden files processed during a test run.
+type registry struct {
+	mu      sync.Mutex
+	updates map[string]*GoldenUpdate
+	// reads is the set of absolute paths read through a File.
+	reads map[string]struct{}
+	// locks serialize access to each golden file, by absolute path.
+	locks map[string]*sync.Mutex
+	// claims records the content each golden file was updated to, or
+	// confirmed to hold, by absolute path.
+	claims map[string]claim
+}
+
+// claim records the content of a golden file, as set by a test.
+type claim struct {
+	sum  [sha256.Size]byte
+	test string
+}
+
+var goldens = newRegistry()
+
+func newRegistry() *registry {
+	return &registry{
+		updates: make(map[string]*GoldenUpdate),
+		reads:   make(map[string]struct{}),
+		locks:   make(map[string]*sync.Mutex),
+		claims:  make(map[string]claim),
+	}
+}
+
+// key returns the key under which path is tracked, so that different
+// spellings of the same path are treated alike.
+func key(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// lock locks the golden file at path, and returns a function to unlock it.
+func (r *registry) lock(path string) func() {
+	k := key(path)
+	r.mu.Lock()
+	l, ok := r.locks[k]
+	if !ok {
+		l = &sync.Mutex{}
+		r.locks[k] = l
+	}
+	r.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// claim records that test set the golden file at path to content. If another
+// test set it to different content earlier in the run, a *ConflictError is
+// returned. As with comparisons, a trailing newline is not significant.
+func (r *registry) claim(path string, content []byte, test string) error {
+	k := key(path)
+	c := claim{sum: sha256.Sum256(bytes.TrimSuffix(content, []byte("\n"))), test: test}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.claims[k]; ok {
+		if existing.sum != c.sum {
+			return &ConflictError{Path: path, Test: test, OtherTest: existing.test}
+		}
+		return nil
+	}
+	r.claims[k] = c
+	return nil
+}
+
+// recordRead records that the file at path was read through a File.
+func (r *registry) recordRead(path string) {
+	k := key(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads[k] = struct{}{}
+}
+
+func (r *registry) wasRead(path string) bool {
+	k := key(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.reads[k]
+	return ok
+}
+
+// recordUpdate records the effect of update mode on a golden file. If the
+// file has already been recorded, the records are merged.
+func (r *registry) recordUpdate(u GoldenUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.updates[u.Path]
+	if !ok {
+		r.updates[u.Path] = &u
+		return
+	}
+	existing.BytesAfter = u.BytesAfter
+	if existing.Status == GoldenUnchanged {
+		existing.Status = u.Status
+	}
+}
+
+// Updates returns a record of each golden file compared in update mode during
+// the current test run, sorted by path. See Updating.
+func Updates() []GoldenUpdate {
+	goldens.mu.Lock()
+	defer goldens.mu.Unlock()
+	updates := make([]GoldenUpdate, 0, len(goldens.updates))
+	for _, u := range goldens.updates {
+		updates = append(updates, *u)
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Path < updates[j].Path })
+	return updates
+}
+
+// WriteUpdateSummary writes a human-readable summary of the golden files
+// created and modified by update mode during the current test run, to w. It
+// is intended to be called from TestMain, after the tests have run:
+//
+//	func TestMain(m *testing.M) {
+//	    code := m.Run()
+//	    _ = diff.WriteUpdateSummary(os.Stdout)
+//	    os.Exit(code)
+//	}
+//
+// If update mode was not enabled, nothing is written.
+func WriteUpdateSummary(w io.Writer) error {
+	updates := Updates()
+	if len(updates) == 0 {
+		return nil
+	}
+	counts := make(map[GoldenStatus]int)
+	for _, u := range updates {
+		counts[u.Status]++
+	}
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "golden files: %d created, %d modified, %d unchanged\n",
+		counts[GoldenCreated], counts[GoldenModified], counts[GoldenUnchanged])
+	for _, u := range updates {
+		if u.Status == GoldenUnchanged {
+			continue
+		}
+		fmt.Fprintf(buf, "  %-9s %s (%d -> %d bytes)", u.Status, u.Path, u.BytesBefore, u.BytesAfter)
+		if u.Test != "" {
+			fmt.Fprintf(buf, " in %s", u.Test)
+		}
+		buf.WriteString("\n")
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// WriteUpdateSummaryJSON writes the records returned by Updates to w, as a
+// JSON array.
+func WriteUpdateSummaryJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(Updates())
+}
+
+// StaleGoldens returns the paths of the files matching pattern which have not
+// been read through a File during the current test run. Patterns use the
+// syntax of path.Match, and additionally "**" matches any number of
+// directories, as in "testdata/**/*.golden".
+//
+// The result is only meaningful once all tests which use golden files have
+// run, so StaleGoldens should be called from TestMain, after m.Run, and not
+// when tests are selected with -run or -skip, or skipped with -short.
+func StaleGoldens(pattern string) ([]string, error) {
+	var stale []string
+	root := globRoot(pattern)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.Mode().IsRegular() && matchGlob(pattern, path) && !goldens.wasRead(path) {
+			stale = append(stale, path)
+		}
+		return nil
+	})
+	return stale, err
+}
+
+// ReportStaleGoldens writes the paths returned by StaleGoldens to w, one per
+// line, and returns the number of stale files found. It is intended to be
+// called from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//	    code := m.Run()
+//	    if n, _ := diff.ReportStaleGoldens(os.Stderr, "testdata/**/*.golden"); n > 0 && code == 0 {
+//	        code = 1
+//	    }
+//	    os.Exit(code)
+//	}
+func ReportStaleGoldens(w io.Writer, pattern string) (int, error) {
+	return staleGoldens(w, pattern, false)
+}
+
+// RemoveStaleGoldens is like ReportStaleGoldens, but additionally deletes
+// each stale file for which update mode is enabled. See Updating.
+//
+// As golden files of tests which did not run are indistinguishable from stale
+// ones, nothing is deleted when tests were selected with -run or -skip, or
+// skipped with -short. The stale files are then reported, and a
+// *PartialRunError is returned.
+func RemoveStaleGoldens(w io.Writer, pattern string) (int, error) {
+	return staleGoldens(w, pattern, true)
+}
+
+func staleGoldens(w io.Writer, pattern string, remove bool) (int, error) {
+	stale, err := StaleGoldens(pattern)
+	if err != nil {
+		return 0, err
+	}
+	var filter string
+	if remove {
+		filter = testFilter()
+	}
+	buf := &strings.Builder{}
+	n := 0
+	for _, path := range stale {
+		if remove && Updating(path) {
+			if filter != "" {
+				err = &PartialRunError{Flag: filter}
+			} else {
+				if err = os.Remove(path); err != nil {
+					break
+				}
+				fmt.Fprintf(buf, "removed stale golden file %s\n", path)
+				n++
+				continue
+			}
+		}
+		fmt.Fprintf(buf, "stale golden file %s\n", path)
+		n++
+	}
+	if _, e := io.WriteString(w, buf.String()); e != nil && err == nil {
+		err = e
+	}
+	return n, err
+}
+
+// testFilter returns the name of the first test flag which was set to select
+// or skip tests, or "" if all tests ran.
+func testFilter() string {
+	for _, name := range []string{"run", "skip", "short"} {
+		if f := flag.Lookup("test." + name); f != nil && f.Value.String() != f.DefValue {
+			return name
+		}
+	}
+	return ""
+}
+
+// callerTest returns the name of the top-level test function in the call
+// stack, or "" if there is none.
+func callerTest() string {
+	pc := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	var name string
+	for {
+		frame, more := frames.Next()
+		if strings.HasSuffix(frame.File, "_test.go") {
+			fn := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+			if parts := strings.Split(fn, "."); len(parts) > 1 && strings.HasPrefix(parts[1], "Test") {
+				name = parts[1]
+			}
+		}
+		if !more {
+			return name
+		}
+	}
+}
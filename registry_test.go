@@ -0,0 +1,230 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// withRegistry replaces the global registry for the duration of a test, and
+// returns a function to restore it.
+func withRegistry() func() {
+	old := goldens
+	goldens = newRegistry()
+	return func() { goldens = old }
+}
+
+func TestRecordUpdate(t *testing.T) {
+	defer withRegistry()()
+	goldens.recordUpdate(GoldenUpdate{Path: "b", Status: GoldenUnchanged, BytesBefore: 3, BytesAfter: 3, Test: "TestB"})
+	goldens.recordUpdate(GoldenUpdate{Path: "b", Status: GoldenModified, BytesBefore: 3, BytesAfter: 5, Test: "TestC"})
+	goldens.recordUpdate(GoldenUpdate{Path: "a", Status: GoldenCreated, BytesAfter: 4})
+	goldens.recordUpdate(GoldenUpdate{Path: "a", Status: GoldenUnchanged, BytesBefore: 4, BytesAfter: 4})
+	expected := []GoldenUpdate{
+		{Path: "a", Status: GoldenCreated, BytesAfter: 4},
+		{Path: "b", Status: GoldenModified, BytesBefore: 3, BytesAfter: 5, Test: "TestB"},
+	}
+	if d := Interface(expected, Updates()); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestWriteUpdateSummary(t *testing.T) {
+	defer withRegistry()()
+	buf := &bytes.Buffer{}
+	if err := WriteUpdateSummary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Unexpected output with no updates: %s", buf.String())
+	}
+	goldens.recordUpdate(GoldenUpdate{Path: "testdata/a.golden", Status: GoldenCreated, BytesAfter: 4, Test: "TestA"})
+	goldens.recordUpdate(GoldenUpdate{Path: "testdata/b.golden", Status: GoldenModified, BytesBefore: 3, BytesAfter: 5})
+	goldens.recordUpdate(GoldenUpdate{Path: "testdata/c.golden", Status: GoldenUnchanged, BytesBefore: 3, BytesAfter: 3})
+	if err := WriteUpdateSummary(buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `golden files: 1 created, 1 modified, 1 unchanged
+  created   testdata/a.golden (0 -> 4 bytes) in TestA
+  modified  testdata/b.golden (3 -> 5 bytes)
+`
+	if d := Text(expected, buf.String()); d != nil {
+		t.Error(d)
+	}
+	buf.Reset()
+	if err := WriteUpdateSummaryJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON := `[
+    {"path": "testdata/a.golden", "status": "created", "bytes_before": 0, "bytes_after": 4, "test": "TestA"},
+    {"path": "testdata/b.golden", "status": "modified", "bytes_before": 3, "bytes_after": 5},
+    {"path": "testdata/c.golden", "status": "unchanged", "bytes_before": 3, "bytes_after": 3}
+]`
+	if d := JSON([]byte(expectedJSON), buf.Bytes()); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestCallerTest(t *testing.T) {
+	if name := callerTest(); name != "TestCallerTest" {
+		t.Errorf("Unexpected result: %s", name)
+	}
+	t.Run("subtest", func(t *testing.T) {
+		if name := callerTest(); name != "TestCallerTest" {
+			t.Errorf("Unexpected result: %s", name)
+		}
+	})
+}
+
+func TestUpdateRegistry(t *testing.T) {
+	defer withRegistry()()
+	dir, err := ioutil.TempDir("", "diff-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if e := ioutil.WriteFile(dir+"/modified.txt", []byte("old"), 0666); e != nil {
+		t.Fatal(e)
+	}
+	if e := ioutil.WriteFile(dir+"/unchanged.txt", []byte("same"), 0666); e != nil {
+		t.Fatal(e)
+	}
+	if e := ioutil.WriteFile(dir+"/unchanged.json", []byte(`{"a":1}`), 0666); e != nil {
+		t.Fatal(e)
+	}
+	defer setenv(t, UpdateEnv, "1")()
+
+	if d := Text(&File{Path: dir + "/created.txt"}, "new"); d != nil {
+		t.Fatal(d)
+	}
+	if d := Text(&File{Path: dir + "/modified.txt"}, "newer"); d != nil {
+		t.Fatal(d)
+	}
+	t.Run("subtest", func(t *testing.T) {
+		AssertText(t, &File{Path: dir + "/unchanged.txt"}, "same")
+	})
+	if d := AsJSON(&File{Path: dir + "/unchanged.json"}, map[string]int{"a": 1}); d != nil {
+		t.Fatal(d)
+	}
+	if d := Text("same", "same"); d != nil {
+		t.Fatal(d)
+	}
+
+	expected := []GoldenUpdate{
+		{Path: dir + "/created.txt", Status: GoldenCreated, BytesAfter: 3, Test: "TestUpdateRegistry"},
+		{Path: dir + "/modified.txt", Status: GoldenModified, BytesBefore: 3, BytesAfter: 5, Test: "TestUpdateRegistry"},
+		{Path: dir + "/unchanged.json", Status: GoldenUnchanged, BytesBefore: 7, BytesAfter: 7, Test: "TestUpdateRegistry"},
+		{Path: dir + "/unchanged.txt", Status: GoldenUnchanged, BytesBefore: 4, BytesAfter: 4, Test: "TestUpdateRegistry/subtest"},
+	}
+	if d := Interface(expected, Updates()); d != nil {
+		t.Error(d)
+	}
+}
+
+func TestStaleGoldens(t *testing.T) {
+	defer withRegistry()()
+	dir, err := ioutil.TempDir("", "diff-stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if e := os.MkdirAll(dir+"/sub", 0777); e != nil {
+		t.Fatal(e)
+	}
+	for _, name := range []string{"used.golden", "stale.golden", "sub/stale.golden", "other.txt"} {
+		if e := ioutil.WriteFile(dir+"/"+name, []byte("foo"), 0666); e != nil {
+			t.Fatal(e)
+		}
+	}
+	if d := Text(&File{Path: dir + "/used.golden"}, "foo"); d != nil {
+		t.Fatal(d)
+	}
+	pattern := dir + "/**/*.golden"
+
+	t.Run("list", func(t *testing.T) {
+		stale, err := StaleGoldens(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{dir + "/stale.golden", dir + "/sub/stale.golden"}
+		if d := Interface(expected, stale); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("missing root", func(t *testing.T) {
+		stale, err := StaleGoldens(dir + "/missing/*.golden")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stale) != 0 {
+			t.Errorf("Unexpected stale files: %v", stale)
+		}
+	})
+	t.Run("report", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		n, err := ReportStaleGoldens(buf, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("Expected 2 stale files, got %d", n)
+		}
+		expected := "stale golden file " + dir + "/stale.golden\n" +
+			"stale golden file " + dir + "/sub/stale.golden\n"
+		if d := Text(expected, buf.String()); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("partial run", func(t *testing.T) {
+		defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+		flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+		flag.String("test.run", "", "")
+		if e := flag.Set("test.run", "TestFoo"); e != nil {
+			t.Fatal(e)
+		}
+		defer setenv(t, UpdateEnv, "true")()
+		buf := &bytes.Buffer{}
+		n, err := RemoveStaleGoldens(buf, pattern)
+		var partialErr *PartialRunError
+		if !errors.As(err, &partialErr) || partialErr.Flag != "run" {
+			t.Fatalf("Expected *PartialRunError for -run, got %v", err)
+		}
+		if n != 2 {
+			t.Errorf("Expected 2 stale files, got %d", n)
+		}
+		expected := "stale golden file " + dir + "/stale.golden\n" +
+			"stale golden file " + dir + "/sub/stale.golden\n"
+		if d := Text(expected, buf.String()); d != nil {
+			t.Error(d)
+		}
+		if _, err := os.Stat(dir + "/sub/stale.golden"); err != nil {
+			t.Errorf("Expected stale file to remain, got %v", err)
+		}
+	})
+	t.Run("remove", func(t *testing.T) {
+		if UpdateMode {
+			t.Skip("update build tag enables update mode for all paths")
+		}
+		defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+		flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+		defer setenv(t, UpdateEnv, dir+"/sub/*")()
+		buf := &bytes.Buffer{}
+		if _, err := RemoveStaleGoldens(buf, pattern); err != nil {
+			t.Fatal(err)
+		}
+		expected := "stale golden file " + dir + "/stale.golden\n" +
+			"removed stale golden file " + dir + "/sub/stale.golden\n"
+		if d := Text(expected, buf.String()); d != nil {
+			t.Error(d)
+		}
+		if _, err := os.Stat(dir + "/sub/stale.golden"); !os.IsNotExist(err) {
+			t.Errorf("Expected stale file to be removed, got %v", err)
+		}
+		if _, err := os.Stat(dir + "/stale.golden"); err != nil {
+			t.Errorf("Expected stale file to remain, got %v", err)
+		}
+	})
+}
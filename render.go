@@ -0,0 +1,204 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/flimzy/diff/engine"
+)
+
+// Granularity selects the unit by which changed lines are compared with one
+// another, to highlight the changed portions of each line.
+type Granularity int
+
+const (
+	// WholeLines disables intra-line highlighting. This is the default.
+	WholeLines Granularity = iota
+	// Words highlights changed words, where a word is a run of letters,
+	// digits and underscores, a run of white space, or a single other
+	// character.
+	Words
+	// Runes highlights changed characters.
+	Runes
+)
+
+// IntraLine enables highlighting of the changed portions of changed lines.
+// Each deleted line which is immediately followed by an inserted line is
+// compared with it by the given granularity. In plain output, the changed
+// portions are marked as [-deleted-] and {+inserted+}.
+func IntraLine(g Granularity) Option {
+	return func(o *options) {
+		o.intraLine = g
+	}
+}
+
+// Markers used to delimit changed portions of lines in plain output.
+const (
+	deleteStart = "[-"
+	deleteEnd   = "-]"
+	insertStart = "{+"
+	insertEnd   = "+}"
+)
+
+// span is a portion of a line, which may or may not have changed.
+type span struct {
+	text    string
+	changed bool
+}
+
+// unified renders hunks in unified diff format.
+func unified(o *options, hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+	p := painter(o.color.enabled())
+	buf := &strings.Builder{}
+	buf.WriteString(p.paint(ansiBold, "--- "+o.from) + "\n")
+	buf.WriteString(p.paint(ansiBold, "+++ "+o.to) + "\n")
+	for _, h := range hunks {
+		buf.WriteString(p.paint(ansiCyan, h.Header()) + "\n")
+		spans := refineHunk(h, o.intraLine)
+		for i, line := range h.Lines {
+			text := strings.TrimSuffix(line.Text, "\n")
+			if spans[i] != nil {
+				text = renderSpans(p, line.Kind, spans[i])
+			}
+			if style := lineStyle(line.Kind); style != "" {
+				buf.WriteString(p.paint(style, line.Kind.prefix()+text))
+			} else {
+				buf.WriteString(line.Kind.prefix() + text)
+			}
+			if strings.HasSuffix(line.Text, "\n") {
+				buf.WriteString("\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// renderSpans renders the spans of a line of the given kind, highlighting the
+// changed spans with markers, or in reverse video when color is enabled.
+func renderSpans(p painter, kind LineKind, spans []span) string {
+	start, end := deleteStart, deleteEnd
+	if kind == Insert {
+		start, end = insertStart, insertEnd
+	}
+	if p {
+		start, end = ansiReverse, ansiNoRev
+	}
+	buf := &strings.Builder{}
+	for _, s := range spans {
+		if s.changed {
+			buf.WriteString(start + s.text + end)
+		} else {
+			buf.WriteString(s.text)
+		}
+	}
+	return buf.String()
+}
+
+// refineHunk returns, for each line of h, the spans of the line, or nil if
+// the line is not to be highlighted. Deleted lines are paired with the
+// inserted lines which immediately follow them, in order.
+func refineHunk(h Hunk, g Granularity) [][]span {
+	spans := make([][]span, len(h.Lines))
+	if g == WholeLines {
+		return spans
+	}
+	lines := h.Lines
+	for i := 0; i < len(lines); {
+		if lines[i].Kind != Delete {
+			i++
+			continue
+		}
+		del := i
+		for i < len(lines) && lines[i].Kind == Delete {
+			i++
+		}
+		ins := i
+		for i < len(lines) && lines[i].Kind == Insert {
+			i++
+		}
+		for n := 0; del+n < ins && ins+n < i; n++ {
+			spans[del+n], spans[ins+n] = refine(lines[del+n].Text, lines[ins+n].Text, g)
+		}
+	}
+	return spans
+}
+
+// refine compares a deleted line with an inserted line, and returns the spans
+// of each. If the lines have nothing in common, nil is returned for both, as
+// highlighting the entire line would add only noise.
+func refine(deleted, inserted string, g Granularity) ([]span, []span) {
+	a := tokenize(strings.TrimSuffix(deleted, "\n"), g)
+	b := tokenize(strings.TrimSuffix(inserted, "\n"), g)
+	codes := engine.Myers(a, b)
+	var common bool
+	for _, c := range codes {
+		if c.Tag == 'e' && strings.TrimSpace(strings.Join(a[c.I1:c.I2], "")) != "" {
+			common = true
+			break
+		}
+	}
+	if !common {
+		return nil, nil
+	}
+	var del, ins []span
+	for _, c := range codes {
+		changed := c.Tag != 'e'
+		if c.Tag != 'i' {
+			del = appendSpan(del, strings.Join(a[c.I1:c.I2], ""), changed)
+		}
+		if c.Tag != 'd' {
+			ins = appendSpan(ins, strings.Join(b[c.J1:c.J2], ""), changed)
+		}
+	}
+	return del, ins
+}
+
+// appendSpan appends text to spans, merging it with the last span if both
+// have the same changed state.
+func appendSpan(spans []span, text string, changed bool) []span {
+	if text == "" {
+		return spans
+	}
+	if last := len(spans) - 1; last >= 0 && spans[last].changed == changed {
+		spans[last].text += text
+		return spans
+	}
+	return append(spans, span{text: text, changed: changed})
+}
+
+// tokenize splits s into tokens of the given granularity.
+func tokenize(s string, g Granularity) []string {
+	var tokens []string
+	if g == Runes {
+		for _, r := range s {
+			tokens = append(tokens, string(r))
+		}
+		return tokens
+	}
+	class := func(r rune) int {
+		switch {
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	start := 0
+	prev := -1
+	for i, r := range s {
+		c := class(r)
+		if i > start && (c != prev || c == 0) {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		prev = c
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		g        Granularity
+		expected []string
+	}{
+		{
+			name:     "words",
+			input:    `"id": "foo_bar1",  x`,
+			g:        Words,
+			expected: []string{`"`, "id", `"`, ":", " ", `"`, "foo_bar1", `"`, ",", "  ", "x"},
+		},
+		{
+			name:     "runes",
+			input:    "añb",
+			g:        Runes,
+			expected: []string{"a", "ñ", "b"},
+		},
+		{
+			name:  "empty",
+			input: "",
+			g:     Words,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := tokenize(test.input, test.g)
+			if d := Interface(test.expected, result); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestIntraLine(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		g                Granularity
+		result           string
+	}{
+		{
+			name:     "disabled",
+			expected: "Date: Tue, 22 Jan 2019 18:44:09 GMT\n",
+			actual:   "Date: Wed, 23 Jan 2019 18:44:09 GMT\n",
+			result:   "--- expected\n+++ actual\n@@ -1 +1 @@\n-Date: Tue, 22 Jan 2019 18:44:09 GMT\n+Date: Wed, 23 Jan 2019 18:44:09 GMT\n",
+		},
+		{
+			name:     "words",
+			expected: "Date: Tue, 22 Jan 2019 18:44:09 GMT\n",
+			actual:   "Date: Wed, 23 Jan 2019 18:44:09 GMT\n",
+			g:        Words,
+			result:   "--- expected\n+++ actual\n@@ -1 +1 @@\n-Date: [-Tue-], [-22-] Jan 2019 18:44:09 GMT\n+Date: {+Wed+}, {+23+} Jan 2019 18:44:09 GMT\n",
+		},
+		{
+			name:     "runes",
+			expected: `{"price": 10}`,
+			actual:   `{"price": 12}`,
+			g:        Runes,
+			result:   "--- expected\n+++ actual\n@@ -1 +1 @@\n-{\"price\": 1[-0-]}\n+{\"price\": 1{+2+}}\n",
+		},
+		{
+			name:     "nothing in common",
+			expected: "foo bar\n",
+			actual:   "baz qux\n",
+			g:        Words,
+			result:   "--- expected\n+++ actual\n@@ -1 +1 @@\n-foo bar\n+baz qux\n",
+		},
+		{
+			name:     "unpaired lines",
+			expected: "a\nfoo 1\nb\n",
+			actual:   "a\nfoo 2\nbar\nb\n",
+			g:        Words,
+			result:   "--- expected\n+++ actual\n@@ -1,3 +1,4 @@\n a\n-foo [-1-]\n+foo {+2+}\n+bar\n b\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := TextWith(test.expected, test.actual, IntraLine(test.g))
+			if d := Text(test.result, result.String()); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
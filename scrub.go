@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Scrubber normalizes text before it is compared, such as to remove values
+// which vary from run to run. Scrubbers are applied to both sides of a
+// comparison, so that they remain comparable.
+type Scrubber func(string) string
+
+// Scrub adds scrubbers to apply, in order, to both the expected and actual
+// text before comparison. Scrub may be given more than once, in which case
+// the scrubbers accumulate. In update mode (see Updating), golden files are
+// written with the scrubbed actual text. For example, to ignore pointer
+// addresses in spew dumps:
+//
+//	diff.InterfaceWith(expected, actual,
+//	    diff.Scrub(diff.ReplaceRegexp(regexp.MustCompile(`0x[0-9a-f]+`), "0x?")))
+func Scrub(scrubbers ...Scrubber) Option {
+	return func(o *options) {
+		o.scrubbers = append(o.scrubbers, scrubbers...)
+	}
+}
+
+// ReplaceRegexp returns a Scrubber which replaces matches of re with repl, as
+// by re.ReplaceAllString, so that repl may refer to submatches, as in "$1".
+func ReplaceRegexp(re *regexp.Regexp, repl string) Scrubber {
+	return func(s string) string {
+		return re.ReplaceAllString(s, repl)
+	}
+}
+
+var trailingSpaceRE = regexp.MustCompile(`(?m)[ \t]+(\r?)$`)
+
+// TrimTrailingSpace is a Scrubber which removes spaces and tabs from the end
+// of each line.
+func TrimTrailingSpace(s string) string {
+	return trailingSpaceRE.ReplaceAllString(s, "$1")
+}
+
+var blankLinesRE = regexp.MustCompile(`\n(?:[ \t]*\r?\n)+`)
+
+// CollapseBlankLines is a Scrubber which replaces each run of blank lines with
+// a single empty line.
+func CollapseBlankLines(s string) string {
+	return blankLinesRE.ReplaceAllString(s, "\n\n")
+}
+
+// NormalizeNewlines is a Scrubber which converts CRLF and lone CR line endings
+// to LF.
+func NormalizeNewlines(s string) string {
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s)
+}
+
+// RedactPath returns a Scrubber which replaces each occurrence of path, which
+// is made absolute if possible, with replacement. Occurrences with either
+// forward or backward slashes are replaced.
+func RedactPath(path, replacement string) Scrubber {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.Clean(path)
+	forward := filepath.ToSlash(path)
+	backward := strings.Replace(forward, "/", `\`, -1)
+	r := strings.NewReplacer(forward, replacement, backward, replacement)
+	return r.Replace
+}
+
+// RedactTempDir is a Scrubber which replaces the paths of temporary files and
+// directories, such as those created by ioutil.TempDir, so that
+// "/tmp/foo123456/bar" becomes "$TMPDIR/bar". That is, the first path element
+// below os.TempDir(), which is typically randomly named, is replaced along
+// with os.TempDir() itself.
+func RedactTempDir(s string) string {
+	dir := filepath.Clean(os.TempDir())
+	re := regexp.MustCompile(regexp.QuoteMeta(dir) + `[/\\][^/\\\s"']+`)
+	return re.ReplaceAllString(s, "$$TMPDIR")
+}
+
+// scrub applies the configured scrubbers to s.
+func (o *options) scrub(s string) string {
+	for _, scrub := range o.scrubbers {
+		s = scrub(s)
+	}
+	return s
+}
+
+// scrubLines applies the configured scrubbers to lines, treated as a single
+// text.
+func (o *options) scrubLines(lines []string) []string {
+	if len(o.scrubbers) == 0 || len(lines) == 0 {
+		return lines
+	}
+	buf := &strings.Builder{}
+	for _, line := range lines {
+		buf.WriteString(strings.TrimRight(line, "\n") + "\n")
+	}
+	s := strings.TrimSuffix(o.scrub(buf.String()), "\n")
+	return strings.Split(s, "\n")
+}
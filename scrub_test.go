@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestScrubbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		scrubber Scrubber
+		input    string
+		expected string
+	}{
+		{
+			name:     "replace regexp",
+			scrubber: ReplaceRegexp(regexp.MustCompile(`\(0x[0-9a-f]+\)`), "(0x?)"),
+			input:    "(*int)(0xc000012345)(5)\n",
+			expected: "(*int)(0x?)(5)\n",
+		},
+		{
+			name:     "replace regexp with submatch",
+			scrubber: ReplaceRegexp(regexp.MustCompile(`id=(\w)\w*`), "id=$1..."),
+			input:    "id=foobar",
+			expected: "id=f...",
+		},
+		{
+			name:     "trim trailing space",
+			scrubber: TrimTrailingSpace,
+			input:    "foo  \nbar\t\r\n  baz \n",
+			expected: "foo\nbar\r\n  baz\n",
+		},
+		{
+			name:     "collapse blank lines",
+			scrubber: CollapseBlankLines,
+			input:    "foo\n\n\n  \nbar\n\nbaz\n",
+			expected: "foo\n\nbar\n\nbaz\n",
+		},
+		{
+			name:     "normalize newlines",
+			scrubber: NormalizeNewlines,
+			input:    "foo\r\nbar\rbaz\n",
+			expected: "foo\nbar\nbaz\n",
+		},
+		{
+			name:     "redact path",
+			scrubber: RedactPath("/home/user/src", "$SRC"),
+			input:    "/home/user/src/foo.go:12\n\\home\\user\\src\\bar.go\n/home/user/other\n",
+			expected: "$SRC/foo.go:12\n$SRC\\bar.go\n/home/user/other\n",
+		},
+		{
+			name:     "redact temp dir",
+			scrubber: RedactTempDir,
+			input:    "open " + filepath.Join(os.TempDir(), "diff-file123", "foo.golden") + ": not found",
+			expected: "open $TMPDIR" + string(filepath.Separator) + "foo.golden: not found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.scrubber(test.input); result != test.expected {
+				t.Errorf("Unexpected result: %q", result)
+			}
+		})
+	}
+}
+
+func TestScrub(t *testing.T) {
+	scrub := Scrub(TrimTrailingSpace, NormalizeNewlines)
+	t.Run("text", func(t *testing.T) {
+		if d := TextWith("foo\nbar\n", "foo  \r\nbar\r\n", scrub); d != nil {
+			t.Error(d)
+		}
+		d := TextWith("foo\nbar\n", "foo  \r\nbaz\r\n", scrub)
+		expected := "--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n foo\n-bar\n+baz\n"
+		if d == nil || d.String() != expected {
+			t.Errorf("Unexpected result:\n%s", d)
+		}
+	})
+	t.Run("accumulate", func(t *testing.T) {
+		o := newOptions([]Option{Scrub(TrimTrailingSpace), Scrub(NormalizeNewlines)})
+		if len(o.scrubbers) != 2 {
+			t.Errorf("Expected 2 scrubbers, got %d", len(o.scrubbers))
+		}
+	})
+	t.Run("slices", func(t *testing.T) {
+		if d := TextSlicesWith([]string{"foo", "", "", "bar"}, []string{"foo", "", "bar"}, Scrub(CollapseBlankLines)); d != nil {
+			t.Error(d)
+		}
+		if d := TextSlicesWith(nil, nil, Scrub(CollapseBlankLines)); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("interface", func(t *testing.T) {
+		a, b := 1, 1
+		pointers := Scrub(ReplaceRegexp(regexp.MustCompile(`0x[0-9a-f]+`), "0x?"))
+		if d := InterfaceWith(struct{ P *int }{&a}, struct{ P *int }{&b}, pointers); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("update", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "diff-scrub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		defer setenv(t, UpdateEnv, "true")()
+		path := filepath.Join(dir, "foo.golden")
+		if d := TextWith(&File{Path: path}, "wrote "+path+"  \n", Scrub(RedactTempDir, TrimTrailingSpace)); d != nil {
+			t.Fatalf("Unexpected result in update mode: %s", d)
+		}
+		expected := "wrote $TMPDIR" + string(filepath.Separator) + "foo.golden"
+		if d := Text(expected, &File{Path: path}); d != nil {
+			t.Errorf("Unexpected golden file content:\n%s", d)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultWidth is the total width of side-by-side output, when none is given.
+const defaultWidth = 120
+
+// SideBySide renders the diff in two columns, with the expected input on the
+// left and the actual input on the right, within width characters. Changed
+// lines are marked with '|' between the columns, deleted lines with '<', and
+// inserted lines with '>'. If width is 0 or less, a width of 120 is used.
+// Intra-line highlighting does not apply to side-by-side output.
+func SideBySide(width int) Option {
+	return func(o *options) {
+		o.sideBySide = true
+		o.width = width
+	}
+}
+
+// LineNumbers causes side-by-side output to include the line number of each
+// line of the expected and actual inputs.
+func LineNumbers() Option {
+	return func(o *options) {
+		o.lineNumbers = true
+	}
+}
+
+// WrapLines causes lines in side-by-side output which are too long for their
+// column to be wrapped onto multiple rows. By default, long lines are
+// truncated, and marked with a trailing '…'.
+func WrapLines() Option {
+	return func(o *options) {
+		o.wrap = true
+	}
+}
+
+// cell is one side of a row of side-by-side output.
+type cell struct {
+	num   int
+	text  string
+	kind  LineKind
+	empty bool
+}
+
+// columns holds the layout of side-by-side output.
+type columns struct {
+	p         painter
+	width     int // width of each column
+	numWidth  int // width of the line numbers within each column, if any
+	textWidth int // width of the text within each column
+	wrap      bool
+}
+
+func sideBySide(o *options, hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+	width := o.width
+	if width <= 0 {
+		width = defaultWidth
+	}
+	c := &columns{
+		p:     painter(o.color.enabled()),
+		width: (width - 3) / 2,
+		wrap:  o.wrap,
+	}
+	if o.lineNumbers {
+		last := hunks[len(hunks)-1]
+		n := last.OldStart + last.OldLines
+		if m := last.NewStart + last.NewLines; m > n {
+			n = m
+		}
+		c.numWidth = len(strconv.Itoa(n))
+	}
+	c.textWidth = c.width
+	if c.numWidth > 0 {
+		c.textWidth -= c.numWidth + 1
+	}
+	if c.textWidth < 1 {
+		c.textWidth = 1
+		c.width = c.textWidth
+		if c.numWidth > 0 {
+			c.width += c.numWidth + 1
+		}
+	}
+
+	buf := &strings.Builder{}
+	from := c.p.paint(ansiBold, pad(truncate(o.from, c.width), c.width))
+	buf.WriteString(strings.TrimRight(from+"   "+c.p.paint(ansiBold, truncate(o.to, c.width)), " ") + "\n")
+	for _, h := range hunks {
+		buf.WriteString(c.p.paint(ansiCyan, h.Header()) + "\n")
+		oldNum, newNum := h.OldStart, h.NewStart
+		lines := h.Lines
+		for i := 0; i < len(lines); {
+			if lines[i].Kind == Context {
+				left := cell{num: oldNum, text: lines[i].Text, kind: Context}
+				right := cell{num: newNum, text: lines[i].Text, kind: Context}
+				c.row(buf, left, ' ', right)
+				oldNum++
+				newNum++
+				i++
+				continue
+			}
+			var dels, ins []Line
+			for i < len(lines) && lines[i].Kind == Delete {
+				dels = append(dels, lines[i])
+				i++
+			}
+			for i < len(lines) && lines[i].Kind == Insert {
+				ins = append(ins, lines[i])
+				i++
+			}
+			for n := 0; n < len(dels) || n < len(ins); n++ {
+				left, right := cell{empty: true}, cell{empty: true}
+				sep := '|'
+				if n < len(dels) {
+					left = cell{num: oldNum, text: dels[n].Text, kind: Delete}
+					oldNum++
+				} else {
+					sep = '>'
+				}
+				if n < len(ins) {
+					right = cell{num: newNum, text: ins[n].Text, kind: Insert}
+					newNum++
+				} else {
+					sep = '<'
+				}
+				c.row(buf, left, sep, right)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// row writes a single row, which may span multiple lines if wrapping is
+// enabled, to buf.
+func (c *columns) row(buf *strings.Builder, left cell, sep rune, right cell) {
+	l, r := c.format(left), c.format(right)
+	for n := 0; n < len(l) || n < len(r); n++ {
+		var lText, rText string
+		if n < len(l) {
+			lText = l[n]
+		}
+		if n < len(r) {
+			rText = strings.TrimRight(r[n], " ")
+		}
+		s := ' '
+		if n == 0 {
+			s = sep
+		}
+		line := c.p.paint(lineStyle(left.kind), pad(lText, c.width)) +
+			" " + string(s) + " " +
+			c.p.paint(lineStyle(right.kind), rText)
+		buf.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+}
+
+// format returns the lines of text which make up a cell, each of which is
+// at most c.width characters long.
+func (c *columns) format(cl cell) []string {
+	if cl.empty {
+		return []string{""}
+	}
+	text := strings.TrimRight(cl.text, "\r\n")
+	text = strings.Replace(text, "\t", "    ", -1)
+	var chunks []string
+	if c.wrap {
+		chunks = wrap(text, c.textWidth)
+	} else {
+		chunks = []string{truncate(text, c.textWidth)}
+	}
+	if c.numWidth == 0 {
+		return chunks
+	}
+	for i, chunk := range chunks {
+		num := ""
+		if i == 0 {
+			num = strconv.Itoa(cl.num)
+		}
+		chunks[i] = fmt.Sprintf("%*s %s", c.numWidth, num, chunk)
+	}
+	return chunks
+}
+
+// truncate shortens text to at most width characters, replacing the final
+// character with '…' if any were removed.
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+// wrap splits text into chunks of at most width characters.
+func wrap(text string, width int) []string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return []string{""}
+	}
+	var chunks []string
+	for len(runes) > width {
+		chunks = append(chunks, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(chunks, string(runes))
+}
+
+// pad pads text with spaces to width characters.
+func pad(text string, width int) string {
+	if n := width - utf8.RuneCountInString(text); n > 0 {
+		return text + strings.Repeat(" ", n)
+	}
+	return text
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSideBySide(t *testing.T) {
+	const (
+		expected = "one\ntwo\nthree\nfour\n"
+		actual   = "one\n2\nthree\nfour\nfive\n"
+	)
+	tests := []struct {
+		name   string
+		result *Result
+		opts   []Option
+		want   string
+	}{
+		{
+			name:   "basic",
+			result: Text(expected, actual),
+			opts:   []Option{SideBySide(23)},
+			want: "expected     actual\n" +
+				"@@ -1,4 +1,5 @@\n" +
+				"one          one\n" +
+				"two        | 2\n" +
+				"three        three\n" +
+				"four         four\n" +
+				"           > five\n",
+		},
+		{
+			name:   "line numbers",
+			result: Text(expected, actual),
+			opts:   []Option{SideBySide(23), LineNumbers()},
+			want: "expected     actual\n" +
+				"@@ -1,4 +1,5 @@\n" +
+				"1 one        1 one\n" +
+				"2 two      | 2 2\n" +
+				"3 three      3 three\n" +
+				"4 four       4 four\n" +
+				"           > 5 five\n",
+		},
+		{
+			name:   "deleted lines",
+			result: Text("a\nb\nc\n", "a\nc\n"),
+			opts:   []Option{SideBySide(11)},
+			want: "exp…   act…\n" +
+				"@@ -1,3 +1,2 @@\n" +
+				"a      a\n" +
+				"b    <\n" +
+				"c      c\n",
+		},
+		{
+			name:   "truncated",
+			result: Text("short\n", "a much longer line\n"),
+			opts:   []Option{SideBySide(23)},
+			want: "expected     actual\n" +
+				"@@ -1 +1 @@\n" +
+				"short      | a much lo…\n",
+		},
+		{
+			name:   "wrapped",
+			result: Text("short\n", "a much longer line\n"),
+			opts:   []Option{SideBySide(23), WrapLines()},
+			want: "expected     actual\n" +
+				"@@ -1 +1 @@\n" +
+				"short      | a much lon\n" +
+				"             ger line\n",
+		},
+		{
+			name:   "wrapped with line numbers",
+			result: Text("short\n", "a much longer line\n"),
+			opts:   []Option{SideBySide(23), WrapLines(), LineNumbers()},
+			want: "expected     actual\n" +
+				"@@ -1 +1 @@\n" +
+				"1 short    | 1 a much l\n" +
+				"               onger li\n" +
+				"               ne\n",
+		},
+		{
+			name:   "color",
+			result: Text("a\n", "b\n"),
+			opts:   []Option{SideBySide(11), Color(ColorAlways)},
+			want: "\x1b[1mexp…\x1b[0m   \x1b[1mact…\x1b[0m\n" +
+				"\x1b[36m@@ -1 +1 @@\x1b[0m\n" +
+				"\x1b[31ma   \x1b[0m | \x1b[32mb\x1b[0m\n",
+		},
+		{
+			name: "from HTTPResponse",
+			result: HTTPResponse(
+				&http.Response{StatusCode: 200, ProtoMajor: 1, ProtoMinor: 1, Header: http.Header{"Foo": []string{"bar"}}},
+				&http.Response{StatusCode: 200, ProtoMajor: 1, ProtoMinor: 1, Header: http.Header{"Foo": []string{"baz"}}},
+			),
+			opts: []Option{SideBySide(41)},
+			want: "expected              actual\n" +
+				"@@ -1,4 +1,4 @@\n" +
+				"HTTP/1.1 200 OK       HTTP/1.1 200 OK\n" +
+				"Foo: bar            | Foo: baz\n" +
+				"Content-Length: 0     Content-Length: 0\n" +
+				"\n",
+		},
+		{
+			name:   "configured at comparison",
+			result: TextWith("a\n", "b\n", SideBySide(11)),
+			want: "exp…   act…\n" +
+				"@@ -1 +1 @@\n" +
+				"a    | b\n",
+		},
+		{
+			name:   "nil result",
+			result: nil,
+			opts:   []Option{SideBySide(11)},
+		},
+	}
+	defer setenv(t, "NO_COLOR", "1")()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if d := Interface(test.want, test.result.Render(test.opts...)); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+)
+
+// archive is a txtar archive, as described by golang.org/x/tools/txtar: an
+// optional leading comment, followed by any number of sections, each
+// introduced by a marker line of the form "-- name --".
+type archive struct {
+	comment  []byte
+	sections []section
+}
+
+type section struct {
+	name string
+	data []byte
+}
+
+// parseArchive parses the txtar archive in data.
+func parseArchive(data []byte) *archive {
+	a := &archive{}
+	var name string
+	a.comment, name, data = findMarker(data)
+	for name != "" {
+		s := section{name: name}
+		s.data, name, data = findMarker(data)
+		a.sections = append(a.sections, s)
+	}
+	return a
+}
+
+// findMarker finds the first marker line in data, and returns the data
+// before it, the name from the marker, and the data after it. If there is
+// no marker, the name is empty.
+func findMarker(data []byte) (before []byte, name string, after []byte) {
+	var i int
+	for {
+		if name, after = isMarker(data[i:]); name != "" {
+			return data[:i], name, after
+		}
+		j := bytes.IndexByte(data[i:], '\n')
+		if j < 0 {
+			return data, "", nil
+		}
+		i += j + 1
+	}
+}
+
+// isMarker reports whether data begins with a marker line, and if so,
+// returns the name and the data following the line.
+func isMarker(data []byte) (name string, after []byte) {
+	if !bytes.HasPrefix(data, []byte("-- ")) {
+		return "", nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data, after = data[:i], data[i+1:]
+	}
+	if !bytes.HasSuffix(data, []byte(" --")) || len(data) < len("-- x --") {
+		return "", nil
+	}
+	return strings.TrimSpace(string(data[3 : len(data)-3])), after
+}
+
+// get returns the data of the named section.
+func (a *archive) get(name string) ([]byte, bool) {
+	for _, s := range a.sections {
+		if s.name == name {
+			return s.data, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces the data of the named section, or appends the section if it
+// does not exist.
+func (a *archive) set(name string, data []byte) {
+	for i, s := range a.sections {
+		if s.name == name {
+			a.sections[i].data = data
+			return
+		}
+	}
+	a.sections = append(a.sections, section{name: name, data: data})
+}
+
+// format returns the serialized archive. Each section's data, as well as the
+// comment, is terminated by a newline.
+func (a *archive) format() []byte {
+	buf := &bytes.Buffer{}
+	buf.Write(fixNL(a.comment))
+	for _, s := range a.sections {
+		buf.WriteString("-- " + s.name + " --\n")
+		buf.Write(fixNL(s.data))
+	}
+	return buf.Bytes()
+}
+
+// fixNL returns data with a trailing newline added, if it is non-empty and
+// lacks one.
+func fixNL(data []byte) []byte {
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return data
+	}
+	return append(append([]byte(nil), data...), '\n')
+}
@@ -0,0 +1,73 @@
+package diff
+
+import "testing"
+
+func TestArchive(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *archive
+		output   string
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: &archive{comment: []byte{}},
+		},
+		{
+			name:     "comment only",
+			input:    "comment\n",
+			expected: &archive{comment: []byte("comment\n")},
+			output:   "comment\n",
+		},
+		{
+			name:  "sections",
+			input: "comment\n-- foo --\nfoo\n-- bar.txt --\n-- baz --\nbaz\nbaz",
+			expected: &archive{
+				comment: []byte("comment\n"),
+				sections: []section{
+					{name: "foo", data: []byte("foo\n")},
+					{name: "bar.txt", data: []byte{}},
+					{name: "baz", data: []byte("baz\nbaz")},
+				},
+			},
+			output: "comment\n-- foo --\nfoo\n-- bar.txt --\n-- baz --\nbaz\nbaz\n",
+		},
+		{
+			name:  "not markers",
+			input: "-- --\n--foo --\n-- foo\n-- foo --",
+			expected: &archive{
+				comment:  []byte("-- --\n--foo --\n-- foo\n"),
+				sections: []section{{name: "foo"}},
+			},
+			output: "-- --\n--foo --\n-- foo\n-- foo --\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := parseArchive([]byte(test.input))
+			if d := Interface(test.expected, a); d != nil {
+				t.Error(d)
+			}
+			if d := Text(test.output, a.format()); d != nil {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestArchiveSet(t *testing.T) {
+	a := parseArchive([]byte("-- foo --\nfoo\n-- bar --\nbar\n"))
+	a.set("foo", []byte("new"))
+	a.set("baz", []byte("baz\n"))
+	expected := "-- foo --\nnew\n-- bar --\nbar\n-- baz --\nbaz\n"
+	if d := Text(expected, a.format()); d != nil {
+		t.Error(d)
+	}
+	if data, ok := a.get("bar"); !ok || string(data) != "bar\n" {
+		t.Errorf("Unexpected section: %q, %t", data, ok)
+	}
+	if _, ok := a.get("qux"); ok {
+		t.Error("Unexpected section qux")
+	}
+}
@@ -1,25 +1,192 @@
 package diff
 
 import (
-	"fmt"
+	"errors"
+	"flag"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
-func update(updateMode bool, expected interface{}, actual string, d *Result) *Result {
-	if d == nil || !updateMode {
+// UpdateEnv is the name of the environment variable which enables update mode
+// at runtime. Its value may be a boolean, as understood by strconv.ParseBool,
+// or a comma-separated list of glob patterns, in which case only golden files
+// whose paths match one of the patterns are updated. Patterns use the syntax
+// of path.Match, and additionally "**" matches any number of directories, as
+// in:
+//
+//	DIFF_UPDATE='testdata/**/*.golden' go test ./...
+const UpdateEnv = "DIFF_UPDATE"
+
+// updateFlagName is the name of the test flag which enables update mode.
+const updateFlagName = "update"
+
+// RegisterUpdateFlag defines the boolean -update flag on flag.CommandLine,
+// unless a flag of that name is already defined. It must be called before the
+// flags are parsed, such as from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//	    diff.RegisterUpdateFlag()
+//	    os.Exit(m.Run())
+//	}
+//
+// A test package which defines its own -update flag, as in
+// `var update = flag.Bool("update", false, "...")`, need not call it.
+func RegisterUpdateFlag() {
+	if flag.Lookup(updateFlagName) == nil {
+		flag.Bool(updateFlagName, false, "update golden files with actual values")
+	}
+}
+
+// Updating reports whether a golden File at path is to be overwritten with the
+// actual value when a difference is detected. This is the case when any of the
+// following is true, subject to the patterns given in UpdateEnv, if any:
+//
+//   - UpdateMode is true, because the package was built with -tags=update
+//   - The -update flag was passed to the test binary, as in `go test -update`.
+//     The flag must be defined, either by the test package itself or with
+//     RegisterUpdateFlag.
+//   - The DIFF_UPDATE environment variable is set; see UpdateEnv
+func Updating(path string) bool {
+	enabled := UpdateMode
+	if f := flag.Lookup(updateFlagName); f != nil {
+		if v, err := strconv.ParseBool(f.Value.String()); err == nil && v {
+			enabled = true
+		}
+	}
+	env := os.Getenv(UpdateEnv)
+	if env == "" {
+		return enabled
+	}
+	if v, err := strconv.ParseBool(env); err == nil {
+		return enabled || v
+	}
+	for _, pattern := range strings.Split(env, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" && matchGlob(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// updating reports whether update mode is enabled for expected, which must be
+// a *File to be eligible for updates.
+func updating(expected interface{}) bool {
+	f, ok := expected.(*File)
+	return ok && Updating(f.diskPath())
+}
+
+func update(updateMode bool, expected interface{}, actual string, d *Result, o *options) *Result {
+	if !updateMode {
 		return d
 	}
 	expectedFile, ok := expected.(*File)
 	if !ok {
 		return d
 	}
-	file, err := os.OpenFile(expectedFile.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666) // nolint: gas
+	// An invalid placeholder must be fixed by hand, rather than overwritten.
+	var placeholderErr *PlaceholderError
+	if errors.As(d.Err(), &placeholderErr) {
+		return d
+	}
+	path := expectedFile.diskPath()
+	record := GoldenUpdate{
+		Path:   expectedFile.name(),
+		Status: GoldenCreated,
+		Test:   o.test,
+	}
+	if record.Test == "" {
+		record.Test = callerTest()
+	}
+	// Parallel tests may share a golden file, so the file is locked while it
+	// is updated, and conflicting actual values are rejected rather than
+	// silently overwriting one another.
+	defer goldens.lock(path)()
+	old, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return &Result{err: &UpdateError{Path: path, Err: err}}
+	}
+	before, beforeErr := expectedFile.decode(old)
+	exists := err == nil && beforeErr == nil
+	content := []byte(actual)
+	if d == nil && exists {
+		// The file may match actual without being identical, as for JSON,
+		// so the existing content is what's claimed.
+		content = before
+	}
+	if e := goldens.claim(record.Path, content, record.Test); e != nil {
+		return &Result{err: &UpdateError{Path: path, Err: e}}
+	}
+	if exists {
+		record.Status = GoldenModified
+		record.BytesBefore = int64(len(before))
+	}
+	if d == nil {
+		record.Status = GoldenUnchanged
+		record.BytesAfter = record.BytesBefore
+		goldens.recordUpdate(record)
+		return nil
+	}
+	data, err := expectedFile.encode(old, []byte(actual))
 	if err != nil {
-		return &Result{err: fmt.Sprintf("Update failed: %s", err)}
+		return &Result{err: &UpdateError{Path: path, Err: err}}
 	}
-	defer file.Close() // nolint: errcheck
-	if _, e := file.WriteString(actual); e != nil {
-		return &Result{err: fmt.Sprintf("Update failed: %s", e)}
+	if e := writeFile(path, data, expectedFile.CreateDirs); e != nil {
+		return &Result{err: &UpdateError{Path: path, Err: e}}
 	}
+	record.BytesAfter = int64(len(actual))
+	goldens.recordUpdate(record)
 	return nil
 }
+
+// defaultFileMode is the mode of newly created golden files.
+const defaultFileMode = 0644
+
+// writeFile atomically replaces the contents of the file at path with data,
+// by writing to a temporary file in the same directory, then renaming it over
+// the original. The mode of an existing file is preserved. If mkdir is true,
+// any missing parent directories are created.
+func writeFile(path string, data []byte, mkdir bool) (err error) {
+	mode := os.FileMode(defaultFileMode)
+	if fi, e := os.Stat(path); e == nil {
+		mode = fi.Mode().Perm()
+	} else if !os.IsNotExist(e) {
+		return e
+	}
+	dir := filepath.Dir(path)
+	if mkdir {
+		if e := os.MkdirAll(dir, 0777); e != nil {
+			return e
+		}
+	}
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		// Report the error in terms of the golden file, rather than the
+		// randomly named temporary file.
+		if pathErr, ok := err.(*os.PathError); ok {
+			pathErr.Path = path
+		}
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,8 +1,12 @@
 package diff
 
 import (
+	"errors"
+	"flag"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -33,7 +37,7 @@ func TestUpdate(t *testing.T) {
 			name:          "Create file",
 			updateMode:    true,
 			expected:      &File{Path: dir + "/create.txt"},
-			diff:          &Result{diff: "xxx"},
+			diff:          &Result{},
 			actual:        "testing",
 			finalExpected: "testing",
 		},
@@ -41,7 +45,7 @@ func TestUpdate(t *testing.T) {
 			name:           "Create failure",
 			updateMode:     true,
 			expected:       &File{Path: dir + "/foo/create.txt"},
-			diff:           &Result{diff: "xxx"},
+			diff:           &Result{},
 			actual:         "testing more",
 			expectedResult: "Update failed: open " + dir + "/foo/create.txt: no such file or directory",
 		},
@@ -57,7 +61,7 @@ func TestUpdate(t *testing.T) {
 				name:          "Ovwerrite",
 				updateMode:    true,
 				expected:      &File{Path: file},
-				diff:          &Result{diff: "xxx"},
+				diff:          &Result{},
 				actual:        "testing update",
 				finalExpected: "testing update",
 			}
@@ -74,7 +78,7 @@ func TestUpdate(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := update(test.updateMode, test.expected, test.actual, test.diff)
+			result := update(test.updateMode, test.expected, test.actual, test.diff, newOptions(nil))
 			if d := Interface(test.expectedResult, result.String()); d != nil {
 				t.Errorf("Unexpected result:\n%s\n", d)
 			}
@@ -87,3 +91,384 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdating(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		env      string
+		path     string
+		expected bool
+	}{
+		{
+			name:     "disabled",
+			path:     "testdata/foo.golden",
+			expected: UpdateMode,
+		},
+		{
+			name:     "flag undefined",
+			flag:     "-",
+			path:     "testdata/foo.golden",
+			expected: UpdateMode,
+		},
+		{
+			name:     "flag",
+			flag:     "true",
+			path:     "testdata/foo.golden",
+			expected: true,
+		},
+		{
+			name:     "env true",
+			env:      "1",
+			path:     "testdata/foo.golden",
+			expected: true,
+		},
+		{
+			name:     "env false",
+			env:      "false",
+			path:     "testdata/foo.golden",
+			expected: UpdateMode,
+		},
+		{
+			name:     "env false, flag",
+			flag:     "true",
+			env:      "false",
+			path:     "testdata/foo.golden",
+			expected: true,
+		},
+		{
+			name:     "matching pattern",
+			env:      "testdata/*.txt, testdata/**/*.golden",
+			path:     "testdata/a/foo.golden",
+			expected: true,
+		},
+		{
+			name:     "non-matching pattern",
+			env:      "testdata/*.txt",
+			path:     "testdata/foo.golden",
+			expected: false,
+		},
+		{
+			name:     "non-matching pattern, flag",
+			flag:     "true",
+			env:      "testdata/*.txt",
+			path:     "testdata/foo.golden",
+			expected: false,
+		},
+	}
+	defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+			if test.flag != "-" {
+				RegisterUpdateFlag()
+				value := test.flag
+				if value == "" {
+					value = "false"
+				}
+				if err := flag.Set("update", value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			defer setenv(t, UpdateEnv, test.env)()
+			if result := Updating(test.path); result != test.expected {
+				t.Errorf("Unexpected result: %t", result)
+			}
+		})
+	}
+}
+
+func TestUpdateAtRuntime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	golden := dir + "/runtime.txt"
+	defer setenv(t, UpdateEnv, dir+"/*.txt")()
+	if d := Text(&File{Path: golden}, "new content"); d != nil {
+		t.Fatalf("Unexpected result: %s", d)
+	}
+	if d := Text("new content", &File{Path: golden}); d != nil {
+		t.Errorf("Unexpected file contents:\n%s\n", d)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("create dirs", func(t *testing.T) {
+		path := dir + "/a/b/create.txt"
+		d := update(true, &File{Path: path, CreateDirs: true}, "nested", &Result{}, newOptions(nil))
+		if d != nil {
+			t.Fatalf("Unexpected result: %s", d)
+		}
+		if d := Text("nested", &File{Path: path}); d != nil {
+			t.Errorf("Unexpected file contents:\n%s\n", d)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := fi.Mode().Perm(); mode != defaultFileMode {
+			t.Errorf("Unexpected mode: %04o", mode)
+		}
+	})
+	t.Run("preserve mode", func(t *testing.T) {
+		path := dir + "/mode.txt"
+		if err := ioutil.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, 0751); err != nil {
+			t.Fatal(err)
+		}
+		if d := update(true, &File{Path: path}, "new", &Result{}, newOptions(nil)); d != nil {
+			t.Fatalf("Unexpected result: %s", d)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := fi.Mode().Perm(); mode != 0751 {
+			t.Errorf("Unexpected mode: %04o", mode)
+		}
+	})
+	t.Run("no temporary files remain", func(t *testing.T) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if strings.HasSuffix(f.Name(), ".tmp") {
+				t.Errorf("Temporary file remains: %s", f.Name())
+			}
+		}
+	})
+	t.Run("target is a directory", func(t *testing.T) {
+		path := dir + "/a"
+		d := update(true, &File{Path: path}, "oops", &Result{}, newOptions(nil))
+		var updateErr *UpdateError
+		if !errors.As(d, &updateErr) {
+			t.Fatalf("Expected *UpdateError, got: %s", d)
+		}
+		if updateErr.Path != path {
+			t.Errorf("Unexpected path: %s", updateErr.Path)
+		}
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			t.Errorf("Directory was clobbered")
+		}
+	})
+}
+
+func TestUpdateComparisons(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if e := os.Mkdir(dir+"/tree", 0777); e != nil {
+		t.Fatal(e)
+	}
+	if e := ioutil.WriteFile(dir+"/tree/foo", []byte("foo"), 0666); e != nil {
+		t.Fatal(e)
+	}
+
+	tests := []struct {
+		name    string
+		compare func(golden *File) *Result
+		content string
+	}{
+		{
+			name: "Interface",
+			compare: func(golden *File) *Result {
+				return Interface(golden, []string{"foo", "bar"})
+			},
+			content: "([]string) (len=2) {\n  (string) (len=3) \"foo\",\n  (string) (len=3) \"bar\"\n}\n",
+		},
+		{
+			name: "JSON",
+			compare: func(golden *File) *Result {
+				return JSON(golden, []byte(`{"foo":"bar"}`))
+			},
+			content: "{\n    \"foo\": \"bar\"\n}",
+		},
+		{
+			name: "DirChecksum",
+			compare: func(golden *File) *Result {
+				return DirChecksum(golden, dir+"/tree")
+			},
+			content: "(map[string]string) (len=1) {\n  (string) (len=3) \"foo\": (string) (len=32) \"acbd18db4cc2f85cedef654fccc4a4d8\"\n}\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			golden := dir + "/" + test.name + ".golden"
+			if !Updating(golden) {
+				if d := test.compare(&File{Path: golden}); d == nil || d.Err() == nil {
+					t.Fatalf("Expected an error for a missing golden file, got: %v", d)
+				}
+			}
+			func() {
+				defer setenv(t, UpdateEnv, "true")()
+				if d := test.compare(&File{Path: golden}); d != nil {
+					t.Fatalf("Unexpected result in update mode: %s", d)
+				}
+			}()
+			if d := Text(test.content, &File{Path: golden}); d != nil {
+				t.Errorf("Unexpected golden file contents:\n%s\n", d)
+			}
+			if d := test.compare(&File{Path: golden}); d != nil {
+				t.Errorf("Unexpected result after update: %s", d)
+			}
+		})
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	defer withRegistry()()
+	dir, err := ioutil.TempDir("", "diff-concurrent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setenv(t, UpdateEnv, "true")()
+
+	t.Run("same actual", func(t *testing.T) {
+		golden := dir + "/same.golden"
+		t.Run("group", func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				t.Run("parallel", func(t *testing.T) {
+					t.Parallel()
+					if d := Text(&File{Path: golden}, "foo\nbar\n"); d != nil {
+						t.Error(d)
+					}
+				})
+			}
+		})
+		// As with -count=2, a second run finds the file already updated.
+		if d := Text(&File{Path: golden}, "foo\nbar\n"); d != nil {
+			t.Error(d)
+		}
+		if d := Text("foo\nbar\n", &File{Path: golden}); d != nil {
+			t.Error(d)
+		}
+	})
+	t.Run("conflicting actual", func(t *testing.T) {
+		golden := dir + "/conflict.golden"
+		if d := Text(&File{Path: golden}, "foo"); d != nil {
+			t.Fatal(d)
+		}
+		d := Text(&File{Path: golden}, "bar")
+		var conflictErr *ConflictError
+		if !errors.As(d, &conflictErr) {
+			t.Fatalf("Expected *ConflictError, got: %v", d)
+		}
+		var updateErr *UpdateError
+		if !errors.As(d, &updateErr) {
+			t.Errorf("Expected *UpdateError, got %T", d.Err())
+		}
+		expected := "Update failed: conflicting updates to " + golden + " by TestConcurrentUpdates and TestConcurrentUpdates"
+		if d.String() != expected {
+			t.Errorf("Unexpected error: %s", d)
+		}
+		if e := Text("foo", &File{Path: golden}); e != nil {
+			t.Errorf("Golden file was overwritten:\n%s", e)
+		}
+	})
+	t.Run("conflict with unchanged", func(t *testing.T) {
+		golden := dir + "/unchanged.golden"
+		if e := ioutil.WriteFile(golden, []byte("foo\n"), 0666); e != nil {
+			t.Fatal(e)
+		}
+		if d := Text(&File{Path: golden}, "foo"); d != nil {
+			t.Fatal(d)
+		}
+		if d := Text(&File{Path: golden}, "bar"); !errors.As(d, new(*ConflictError)) {
+			t.Errorf("Expected *ConflictError, got: %v", d)
+		}
+	})
+}
+
+func TestRegisterUpdateFlag(t *testing.T) {
+	defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+	update := flag.Bool("update", false, "defined by the caller")
+	RegisterUpdateFlag()
+	if err := flag.CommandLine.Parse([]string{"-update"}); err != nil {
+		t.Fatal(err)
+	}
+	if !*update {
+		t.Errorf("Expected the caller's flag to be set")
+	}
+	if !Updating("testdata/foo.golden") {
+		t.Errorf("Expected update mode to be enabled")
+	}
+}
+
+func TestUpdateHTTP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff-http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		old     string
+		compare func(golden *File) *Result
+		content string
+	}{
+		{
+			name: "request",
+			old:  "GET /old HTTP/1.1\r\nHost: example.com\r\n\r\n",
+			compare: func(golden *File) *Result {
+				req, _ := http.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+				return HTTPRequest(golden, req)
+			},
+			content: "GET /foo HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		},
+		{
+			name: "response",
+			old:  "HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n",
+			compare: func(golden *File) *Result {
+				return HTTPResponse(golden, &http.Response{
+					StatusCode:    http.StatusOK,
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					ContentLength: 2,
+					Header:        http.Header{"Foo": {"bar"}},
+					Body:          ioutil.NopCloser(strings.NewReader("ok")),
+				})
+			},
+			content: "HTTP/1.1 200 OK\r\nContent-Length: 2\r\nFoo: bar\r\n\r\nok",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			golden := dir + "/" + test.name + ".golden"
+			if e := ioutil.WriteFile(golden, []byte(test.old), 0666); e != nil {
+				t.Fatal(e)
+			}
+			func() {
+				defer setenv(t, UpdateEnv, "true")()
+				if d := test.compare(&File{Path: golden}); d != nil {
+					t.Fatalf("Unexpected result in update mode: %s", d)
+				}
+			}()
+			content, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := Interface(test.content, string(content)); d != nil {
+				t.Errorf("Unexpected golden file contents:\n%s\n", d)
+			}
+			if d := test.compare(&File{Path: golden}); d != nil {
+				t.Errorf("Unexpected result after update: %s", d)
+			}
+		})
+	}
+}